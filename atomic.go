@@ -0,0 +1,63 @@
+package bitset
+
+import (
+	"sync/atomic"
+)
+
+// Only methods in this file are safe to call concurrently with each other.
+// they do not extend the vector.
+
+// SetAtomic sets 1 to bit atomically.
+// if i is out of vector then returns false.
+func (b *BitSet) SetAtomic(i uint) bool {
+	idx := i >> log2WordSize
+	if !b.InRange(i) {
+		return false
+	}
+	mask := b.bitMask(i)
+	addr := &b.vec[idx]
+	for {
+		old := atomic.LoadUint64(addr)
+		if old&mask != 0 || atomic.CompareAndSwapUint64(addr, old, old|mask) {
+			return true
+		}
+	}
+}
+
+// UnsetAtomic sets 0 to bit atomically.
+// if i is out of vector then returns false.
+func (b *BitSet) UnsetAtomic(i uint) bool {
+	idx := i >> log2WordSize
+	if !b.InRange(i) {
+		return false
+	}
+	mask := b.bitMask(i)
+	addr := &b.vec[idx]
+	for {
+		old := atomic.LoadUint64(addr)
+		if old&mask == 0 || atomic.CompareAndSwapUint64(addr, old, old&^mask) {
+			return true
+		}
+	}
+}
+
+// TestAndSet sets 1 to bit atomically and returns the previous value and true.
+// only one of concurrent callers for the same bit gets false as old.
+// if i is out of vector then returns false as ok.
+func (b *BitSet) TestAndSet(i uint) (old bool, ok bool) {
+	idx := i >> log2WordSize
+	if !b.InRange(i) {
+		return false, false
+	}
+	mask := b.bitMask(i)
+	addr := &b.vec[idx]
+	for {
+		v := atomic.LoadUint64(addr)
+		if v&mask != 0 {
+			return true, true
+		}
+		if atomic.CompareAndSwapUint64(addr, v, v|mask) {
+			return false, true
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package bitset
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestBitSet_SetAtomic_UnsetAtomic(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			b := newBitSetWith(t, 8*3, endian, nil)
+			var wg sync.WaitGroup
+			for i := uint(0); i < 8*3*8; i++ {
+				wg.Add(1)
+				go func(i uint) {
+					defer wg.Done()
+					if !b.SetAtomic(i) {
+						t.Errorf("failed to set %v", i)
+					}
+				}(i)
+			}
+			wg.Wait()
+			if !b.All() {
+				t.Errorf("some bits are lost count : %v", b.Count())
+			}
+
+			for i := uint(0); i < 8*3*8; i += 2 {
+				wg.Add(1)
+				go func(i uint) {
+					defer wg.Done()
+					if !b.UnsetAtomic(i) {
+						t.Errorf("failed to unset %v", i)
+					}
+				}(i)
+			}
+			wg.Wait()
+			for i := uint(0); i < 8*3*8; i++ {
+				if expected := i%2 == 1; b.Get(i) != expected {
+					t.Errorf("get(%v) == %v, expected %v", i, b.Get(i), expected)
+				}
+			}
+
+			if b.SetAtomic(8*3*8) || b.UnsetAtomic(8*3*8) {
+				t.Errorf("invalid to success to set next to the last bit")
+			}
+		})
+	}
+}
+
+func TestBitSet_TestAndSet(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			const workers = 8
+			b := newBitSetWith(t, 8*3, endian, nil)
+			claimed := make([][]uint, workers)
+			var wg sync.WaitGroup
+			for w := 0; w < workers; w++ {
+				wg.Add(1)
+				go func(w int) {
+					defer wg.Done()
+					for i := uint(0); i < 8*3*8; i++ {
+						old, ok := b.TestAndSet(i)
+						if !ok {
+							t.Errorf("failed to test and set %v", i)
+						}
+						if !old {
+							claimed[w] = append(claimed[w], i)
+						}
+					}
+				}(w)
+			}
+			wg.Wait()
+			seen := make(map[uint]bool)
+			for _, c := range claimed {
+				for _, i := range c {
+					if seen[i] {
+						t.Errorf("bit %v is claimed twice", i)
+					}
+					seen[i] = true
+				}
+			}
+			if len(seen) != 8*3*8 || !b.All() {
+				t.Errorf("claimed %v bits, expected %v", len(seen), 8*3*8)
+			}
+			if _, ok := b.TestAndSet(8 * 3 * 8); ok {
+				t.Errorf("invalid to success to test and set next to the last bit")
+			}
+		})
+	}
+}
@@ -88,3 +88,253 @@ func BenchmarkSwap(b *testing.B) {
 		swapUint64(randomSet64[i%randomSize])
 	}
 }
+
+// swapUint64Masks is former implementation of swapUint64 to compare with.
+func swapUint64Masks(n uint64) uint64 {
+	return ((n & 0x00000000000000FF) << 56) |
+		((n & 0x000000000000FF00) << 40) |
+		((n & 0x0000000000FF0000) << 24) |
+		((n & 0x00000000FF000000) << 8) |
+		((n & 0x000000FF00000000) >> 8) |
+		((n & 0x0000FF0000000000) >> 24) |
+		((n & 0x00FF000000000000) >> 40) |
+		((n & 0xFF00000000000000) >> 56)
+}
+
+var swapSink uint64
+
+func BenchmarkSwap_ReverseBytes(b *testing.B) {
+	var v uint64
+	for i := 0; i < b.N; i++ {
+		v ^= swapUint64(randomSet64[i%randomSize] ^ v)
+	}
+	swapSink = v
+}
+
+func BenchmarkSwap_Masks(b *testing.B) {
+	var v uint64
+	for i := 0; i < b.N; i++ {
+		v ^= swapUint64Masks(randomSet64[i%randomSize] ^ v)
+	}
+	swapSink = v
+}
+
+func BenchmarkBitSet_Or_SameEndian(b *testing.B) {
+	benchmarkBitSetOp(b, binary.LittleEndian, binary.LittleEndian, (*BitSet).Or)
+}
+
+func BenchmarkBitSet_Or_CrossEndian(b *testing.B) {
+	benchmarkBitSetOp(b, binary.LittleEndian, binary.BigEndian, (*BitSet).Or)
+}
+
+func BenchmarkBitSet_AndNot_SameEndian(b *testing.B) {
+	benchmarkBitSetOp(b, binary.LittleEndian, binary.LittleEndian, (*BitSet).AndNot)
+}
+
+func BenchmarkBitSet_AndNot_CrossEndian(b *testing.B) {
+	benchmarkBitSetOp(b, binary.LittleEndian, binary.BigEndian, (*BitSet).AndNot)
+}
+
+func benchmarkBitSetOp(b *testing.B, order1, order2 binary.ByteOrder, op func(bv1, bv2 *BitSet) error) {
+	const size = 1024 * 1024
+	bv1, err := New(make([]byte, size), order1, false)
+	if err != nil {
+		b.Fatal(err)
+	}
+	bv2, err := New(make([]byte, size), order2, false)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, v := range randomSet {
+		bv2.Set(v)
+	}
+	b.SetBytes(size)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		op(bv1, bv2)
+	}
+}
+
+func BenchmarkSetIterator_LittleEndian(b *testing.B) {
+	benchmarkSetIterator(b, binary.LittleEndian)
+}
+
+func BenchmarkSetIterator_BigEndian(b *testing.B) {
+	benchmarkSetIterator(b, binary.BigEndian)
+}
+
+func benchmarkSetIterator(b *testing.B, order binary.ByteOrder) {
+	bv := newDenseBitSet(b, order)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		it := bv.Iterator()
+		for _, ok := it.Next(); ok; _, ok = it.Next() {
+		}
+	}
+}
+
+func BenchmarkFindFirstOneLoop_LittleEndian(b *testing.B) {
+	benchmarkFindFirstOneLoop(b, binary.LittleEndian)
+}
+
+func BenchmarkFindFirstOneLoop_BigEndian(b *testing.B) {
+	benchmarkFindFirstOneLoop(b, binary.BigEndian)
+}
+
+func benchmarkFindFirstOneLoop(b *testing.B, order binary.ByteOrder) {
+	bv := newDenseBitSet(b, order)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for v, ok := bv.FindFirstOne(0); ok; v, ok = bv.FindFirstOne(v + 1) {
+		}
+	}
+}
+
+func newDenseBitSet(b *testing.B, order binary.ByteOrder) *BitSet {
+	bv, err := New(make([]byte, randomSize/8), order, false)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, v := range randomSet {
+		bv.Set(v)
+	}
+	return bv
+}
+
+func BenchmarkBitSet_ForEachSet_LittleEndian(b *testing.B) {
+	benchmarkBitSetForEachSet(b, binary.LittleEndian)
+}
+
+func BenchmarkBitSet_ForEachSet_BigEndian(b *testing.B) {
+	benchmarkBitSetForEachSet(b, binary.BigEndian)
+}
+
+func benchmarkBitSetForEachSet(b *testing.B, order binary.ByteOrder) {
+	bv := newDenseBitSet(b, order)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bv.ForEachSet(func(uint) bool { return true })
+	}
+}
+
+func BenchmarkBitSet_FindFirstOne_Sparse_LittleEndian(b *testing.B) {
+	benchmarkBitSetFindFirstOneSparse(b, binary.LittleEndian)
+}
+
+func BenchmarkBitSet_FindFirstOne_Sparse_BigEndian(b *testing.B) {
+	benchmarkBitSetFindFirstOneSparse(b, binary.BigEndian)
+}
+
+func benchmarkBitSetFindFirstOneSparse(b *testing.B, order binary.ByteOrder) {
+	const size = 4 * 1024 * 1024
+	bv, err := New(make([]byte, size), order, false)
+	if err != nil {
+		b.Fatal(err)
+	}
+	bv.Set(size*8 - 100)
+	b.SetBytes(size)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bv.FindFirstOne(0)
+	}
+}
+
+func BenchmarkBitSet_ShiftLeft_LittleEndian(b *testing.B) {
+	benchmarkBitSetShiftLeft(b, binary.LittleEndian)
+}
+
+func BenchmarkBitSet_ShiftLeft_BigEndian(b *testing.B) {
+	benchmarkBitSetShiftLeft(b, binary.BigEndian)
+}
+
+func benchmarkBitSetShiftLeft(b *testing.B, order binary.ByteOrder) {
+	bv := newDenseBitSet(b, order)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bv.ShiftLeft(3)
+	}
+}
+
+func BenchmarkBitSet_CountRange_LittleEndian(b *testing.B) {
+	benchmarkBitSetCountRange(b, binary.LittleEndian)
+}
+
+func BenchmarkBitSet_CountRange_BigEndian(b *testing.B) {
+	benchmarkBitSetCountRange(b, binary.BigEndian)
+}
+
+func benchmarkBitSetCountRange(b *testing.B, order binary.ByteOrder) {
+	bv := newDenseBitSet(b, order)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bv.CountRange(3, randomSize-3)
+	}
+}
+
+func BenchmarkBitSet_Count_LittleEndian(b *testing.B) {
+	benchmarkBitSetCount(b, binary.LittleEndian, func(bv *BitSet) { bv.Count() })
+}
+
+func BenchmarkBitSet_Count_BigEndian(b *testing.B) {
+	benchmarkBitSetCount(b, binary.BigEndian, func(bv *BitSet) { bv.Count() })
+}
+
+func BenchmarkBitSet_CountWords_LittleEndian(b *testing.B) {
+	benchmarkBitSetCount(b, binary.LittleEndian, func(bv *BitSet) { bv.CountWords() })
+}
+
+func BenchmarkBitSet_CountWords_BigEndian(b *testing.B) {
+	benchmarkBitSetCount(b, binary.BigEndian, func(bv *BitSet) { bv.CountWords() })
+}
+
+func benchmarkBitSetCount(b *testing.B, order binary.ByteOrder, count func(bv *BitSet)) {
+	const size = 1024 * 1024
+	bv, err := New(make([]byte, size), order, false)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, v := range randomSet {
+		bv.Set(v * 128)
+	}
+	b.SetBytes(size)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		count(bv)
+	}
+}
+
+func BenchmarkBitSet_Rank(b *testing.B) {
+	bv := newDenseBitSet(b, binary.BigEndian)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bv.Rank(randomSet[i%randomSize])
+	}
+}
+
+func BenchmarkRankIndex_Rank(b *testing.B) {
+	ri := newDenseBitSet(b, binary.BigEndian).BuildRankIndex()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ri.Rank(randomSet[i%randomSize])
+	}
+}
+
+func BenchmarkBitSet_AppendTo(b *testing.B) {
+	bv := newDenseBitSet(b, binary.BigEndian)
+	buf := bv.AppendTo(nil)
+	b.SetBytes(int64(len(buf)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf = bv.AppendTo(buf[:0])
+	}
+}
+
+func BenchmarkBitSet_MarshalBinary(b *testing.B) {
+	bv := newDenseBitSet(b, binary.BigEndian)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bv.MarshalBinary()
+	}
+}
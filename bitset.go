@@ -1,9 +1,12 @@
 package bitset
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
 	"math/bits"
 	"reflect"
+	"strconv"
 	"unsafe"
 	"encoding/binary"
 )
@@ -15,6 +18,12 @@ const (
 	mask00111000        = 0x0000000000000038
 	mask00000111        = 0x0000000000000007
 	allBits      uint64 = 0xffffffffffffffff
+
+	// maxStringBits is the max number of bits printed by String
+	maxStringBits = 1024
+	// maxVecWords is the max number of words allocated by extension.
+	// 1 << 28 on 32-bit and 1 << 44 on 64-bit, under the max allocation size of runtime.
+	maxVecWords = 1 << (28 + 16*(^uint(0)>>63))
 )
 
 // errors
@@ -22,17 +31,41 @@ var (
 	ErrInvalidEndianness = errors.New("unsupported endianness")
 	ErrInvalidLength     = errors.New("len(buffer) for zcbit must be N * 8")
 	ErrUnsupportedArch   = errors.New("unsupported host endianness")
+	ErrLengthMismatch    = errors.New("length of bit sets does not match")
+	ErrInvalidFormat     = errors.New("invalid binary format of bit set")
+	ErrUnalignedBuffer   = errors.New("buffer for zcbit must be aligned to 8 bytes")
+	ErrOutOfRange        = errors.New("index out of range")
+	ErrInconsistent      = errors.New("inconsistent state of bit set")
+	ErrTooLarge          = errors.New("bit set exceeds max size to read")
+	ErrAliasingBuffers   = errors.New("buffers of bit sets overlap")
 )
 
 func swapUint64(n uint64) uint64 {
-	return ((n & 0x00000000000000FF) << 56) |
-		((n & 0x000000000000FF00) << 40) |
-		((n & 0x0000000000FF0000) << 24) |
-		((n & 0x00000000FF000000) << 8) |
-		((n & 0x000000FF00000000) >> 8) |
-		((n & 0x0000FF0000000000) >> 24) |
-		((n & 0x00FF000000000000) >> 40) |
-		((n & 0xFF00000000000000) >> 56)
+	return bits.ReverseBytes64(n)
+}
+
+// conv converts word between host order and stored order.
+func (b *BitSet) conv(v uint64) uint64 {
+	if b.swap {
+		return swapUint64(v)
+	}
+	return v
+}
+
+// order returns the byte order of stored words.
+func (b *BitSet) order() binary.ByteOrder {
+	if b.swap == (hostEndian == binary.LittleEndian) {
+		return binary.BigEndian
+	}
+	return binary.LittleEndian
+}
+
+// bitMask returns the mask of bit i in stored byte order.
+func (b *BitSet) bitMask(i uint) uint64 {
+	if b.swap {
+		return 1 << (wordBits - (i & mask00111000) - 8) << (i & mask00000111)
+	}
+	return 1 << (i & (wordBits - 1))
 }
 
 // BitSet is bit vector component
@@ -41,33 +74,365 @@ type BitSet struct {
 	orig   []byte
 	swap   bool
 	extend bool
+	// length is the number of meaningful bits.
+	// bits in [length, len(vec)*wordBits) are padding and always 0.
+	length uint
+	// count is the number of set bits maintained while cached is true.
+	cached bool
+	count  uint
+}
+
+func checkOrder(order binary.ByteOrder) error {
+	if order != binary.LittleEndian && order != binary.BigEndian {
+		return ErrInvalidEndianness
+	} else if hostEndian != binary.LittleEndian && hostEndian != binary.BigEndian {
+		return ErrUnsupportedArch
+	}
+	return nil
 }
 
 // New create *BitSet
 func New(b []byte, order binary.ByteOrder, extend bool) (*BitSet, error) {
 	if len(b)%8 != 0 {
 		return nil, ErrInvalidLength
-	} else if order != binary.LittleEndian && order != binary.BigEndian {
-		return nil, ErrInvalidEndianness
-	} else if hostEndian != binary.LittleEndian && hostEndian != binary.BigEndian {
-		return nil, ErrUnsupportedArch
+	} else if err := checkOrder(order); err != nil {
+		return nil, err
+	}
+	var vec []uint64
+	if cap(b) > 0 {
+		data := uintptr(unsafe.Pointer(&b[:cap(b)][0]))
+		if data&(wordBytes-1) != 0 {
+			return nil, ErrUnalignedBuffer
+		}
+		header := (*reflect.SliceHeader)(unsafe.Pointer(&vec))
+		header.Data = data
+		header.Len = len(b) / wordBytes
+		header.Cap = cap(b) / wordBytes
 	}
-	header := *(*reflect.SliceHeader)(unsafe.Pointer(&b))
-	header.Len /= wordBytes
-	header.Cap /= wordBytes
 
 	return &BitSet{
-		vec:    *(*[]uint64)(unsafe.Pointer(&header)),
+		vec:    vec,
 		orig:   b, // refrain GC
 		swap:   order != hostEndian,
 		extend: extend,
+		length: uint(len(b)) * 8,
 	}, nil
 }
 
+// NewCopy create *BitSet holding a copy of b.
+// created BitSet does not share memory with b and b need not be aligned.
+func NewCopy(b []byte, order binary.ByteOrder, extend bool) (*BitSet, error) {
+	if len(b)%8 != 0 {
+		return nil, ErrInvalidLength
+	} else if err := checkOrder(order); err != nil {
+		return nil, err
+	}
+	nb := &BitSet{
+		vec:    make([]uint64, len(b)/wordBytes),
+		swap:   order != hostEndian,
+		extend: extend,
+		length: uint(len(b)) * 8,
+	}
+	copy(nb.Bytes(), b)
+	return nb, nil
+}
+
+// NewPadded create *BitSet holding a copy of b of any length.
+// the last partial word is filled with 0 up to 8 bytes as a shorter integer
+// in order, and BitLen() is set to len(b) * 8 to omit padding bits.
+// created BitSet does not share memory with b.
+func NewPadded(b []byte, order binary.ByteOrder, extend bool) (*BitSet, error) {
+	if err := checkOrder(order); err != nil {
+		return nil, err
+	}
+	nb := &BitSet{
+		vec:    make([]uint64, (len(b)+wordBytes-1)/wordBytes),
+		swap:   order != hostEndian,
+		extend: extend,
+		length: uint(len(b)) * 8,
+	}
+	buf := nb.Bytes()
+	full := len(b) - len(b)%wordBytes
+	copy(buf, b[:full])
+	if order == binary.BigEndian {
+		copy(buf[len(buf)-(len(b)-full):], b[full:])
+	} else {
+		copy(buf[full:], b[full:])
+	}
+	return nb, nil
+}
+
+// NewFromUint64 create *BitSet from words without copy.
+// memory of words is treated as stored in order.
+func NewFromUint64(words []uint64, order binary.ByteOrder, extend bool) (*BitSet, error) {
+	if err := checkOrder(order); err != nil {
+		return nil, err
+	}
+	return &BitSet{
+		vec:    words,
+		swap:   order != hostEndian,
+		extend: extend,
+		length: uint(len(words)) * wordBits,
+	}, nil
+}
+
+// NewWithLen create *BitSet like New and sets BitLen() to bitLen.
+// bits after bitLen are padding, they are cleared in b and can not be set.
+// if bitLen is out of b then returns ErrInvalidLength.
+func NewWithLen(b []byte, bitLen uint, order binary.ByteOrder, extend bool) (*BitSet, error) {
+	if bitLen > uint(len(b))*8 {
+		return nil, ErrInvalidLength
+	}
+	nb, err := New(b, order, extend)
+	if err != nil {
+		return nil, err
+	}
+	nb.length = bitLen
+	nb.clearPadding()
+	return nb, nil
+}
+
+// FromIndices create *BitSet of bitLen bits with bits at indices set.
+// if an index is out of bitLen then returns ErrOutOfRange.
+// if bitLen is too large to allocate then returns ErrInvalidLength.
+func FromIndices(indices []uint, bitLen uint, order binary.ByteOrder, extend bool) (*BitSet, error) {
+	if err := checkOrder(order); err != nil {
+		return nil, err
+	}
+	size := bitLen >> log2WordSize
+	if bitLen&(wordBits-1) != 0 {
+		size++
+	}
+	if size > maxVecWords {
+		return nil, ErrInvalidLength
+	}
+	nb := &BitSet{
+		vec:    make([]uint64, size),
+		swap:   order != hostEndian,
+		extend: extend,
+		length: bitLen,
+	}
+	for _, i := range indices {
+		if i >= bitLen {
+			return nil, ErrOutOfRange
+		}
+		nb.vec[i>>log2WordSize] |= nb.bitMask(i)
+	}
+	return nb, nil
+}
+
+// Validate checks internal state of b is consistent.
+func (b *BitSet) Validate() error {
+	if err := checkOrder(b.order()); err != nil {
+		return err
+	}
+	if b.length > uint(len(b.vec))*wordBits {
+		return ErrInconsistent
+	}
+	if b.orig != nil && cap(b.orig)/wordBytes < cap(b.vec) {
+		return ErrInconsistent
+	}
+	if b.cached && b.count != countWords(b.vec) {
+		return ErrInconsistent
+	}
+	// padding bits must be 0
+	if countWords(b.vec) != b.CountRange(0, b.length) {
+		return ErrInconsistent
+	}
+	return nil
+}
+
+// Clone creates *BitSet holding a copy of the vector.
+// cloned BitSet does not share memory with b.
+func (b *BitSet) Clone() *BitSet {
+	vec := make([]uint64, len(b.vec))
+	copy(vec, b.vec)
+	return &BitSet{
+		vec:    vec,
+		swap:   b.swap,
+		extend: b.extend,
+		length: b.length,
+		cached: b.cached,
+		count:  b.count,
+	}
+}
+
+// Shrink creates *BitSet holding a copy of the vector without trailing words of all 0.
+// at least one word is kept and BitLen() is clamped to the kept words.
+// shrunk BitSet does not share memory with b.
+func (b *BitSet) Shrink() *BitSet {
+	n := len(b.vec)
+	for n > 1 && b.vec[n-1] == 0 {
+		n--
+	}
+	if n == 0 {
+		n = 1
+	}
+	vec := make([]uint64, n)
+	copy(vec, b.vec)
+	length := b.length
+	if length > uint(n)*wordBits {
+		length = uint(n) * wordBits
+	}
+	return &BitSet{
+		vec:    vec,
+		swap:   b.swap,
+		extend: b.extend,
+		length: length,
+		cached: b.cached,
+		count:  b.count,
+	}
+}
+
+// Swapped checks the vector is stored in byte order different from HostEndian()
+// and each operation pays the cost of swapping.
+func (b *BitSet) Swapped() bool {
+	return b.swap
+}
+
+// SubSlice create *BitSet sharing words in [wordStart, wordEnd) of b.
+// modification through the sub slice is visible in b, but not in the cached count of b.
+// sub slice is not extended.
+func (b *BitSet) SubSlice(wordStart, wordEnd int) (*BitSet, error) {
+	if wordStart < 0 || wordEnd < wordStart || wordEnd > len(b.vec) {
+		return nil, ErrOutOfRange
+	}
+	// padding of b is kept as padding of the sub slice
+	var length uint
+	if start := uint(wordStart) * wordBits; b.length > start {
+		length = b.length - start
+	}
+	if max := uint(wordEnd-wordStart) * wordBits; length > max {
+		length = max
+	}
+	return &BitSet{
+		vec:    b.vec[wordStart:wordEnd:wordEnd],
+		orig:   b.orig, // refrain GC
+		swap:   b.swap,
+		length: length,
+	}, nil
+}
+
+// BitLen returns the number of meaningful bits.
+func (b *BitSet) BitLen() uint {
+	return b.length
+}
+
+// SetBitLen sets the number of meaningful bits.
+// bits after n become padding, they are cleared and can not be set until extended.
+// if n is out of vector then returns false.
+func (b *BitSet) SetBitLen(n uint) bool {
+	if n > uint(len(b.vec))*wordBits {
+		return false
+	}
+	b.length = n
+	b.clearPadding()
+	b.recount()
+	return true
+}
+
+// clearPadding sets 0 to padding bits after BitLen().
+// it does not update the cached count.
+func (b *BitSet) clearPadding() {
+	idx := int(b.length >> log2WordSize)
+	if idx >= len(b.vec) {
+		return
+	}
+	if offset := b.length & (wordBits - 1); offset != 0 {
+		b.vec[idx] &= b.conv(^(allBits << offset))
+		idx++
+	}
+	for i := idx; i < len(b.vec); i++ {
+		b.vec[i] = 0
+	}
+}
+
+// WordCount returns the number of words the vector holds.
+func (b *BitSet) WordCount() int {
+	return len(b.vec)
+}
+
+// Word returns i-th word in host byte order.
+// if i is out of vector then returns 0.
+func (b *BitSet) Word(i int) uint64 {
+	if i < 0 || i >= len(b.vec) {
+		return 0
+	}
+	return b.conv(b.vec[i])
+}
+
+// SetWord sets v in host byte order to i-th word.
+// bits of v after BitLen() are ignored.
+// if i is out of vector then returns false.
+func (b *BitSet) SetWord(i int, v uint64) bool {
+	if i < 0 || i >= len(b.vec) {
+		return false
+	}
+	if start := uint(i) * wordBits; start >= b.length {
+		v = 0
+	} else if b.length-start < wordBits {
+		v &^= allBits << (b.length - start)
+	}
+	if b.cached {
+		b.count = b.count - uint(bits.OnesCount64(b.vec[i])) + uint(bits.OnesCount64(v))
+	}
+	b.vec[i] = b.conv(v)
+	return true
+}
+
+// ToUint64Slice returns a copy of words in host byte order.
+// it allocates a new slice for each call.
+func (b *BitSet) ToUint64Slice() []uint64 {
+	words := make([]uint64, len(b.vec))
+	if b.swap {
+		for i, v := range b.vec {
+			words[i] = swapUint64(v)
+		}
+	} else {
+		copy(words, b.vec)
+	}
+	return words
+}
+
+// Bytes returns the vector as []byte in stored byte order.
+// returned slice shares memory with b, so it can be passed to New with
+// the same byte order.
+func (b *BitSet) Bytes() []byte {
+	if len(b.vec) == 0 {
+		return nil
+	}
+	var buf []byte
+	header := (*reflect.SliceHeader)(unsafe.Pointer(&buf))
+	header.Data = uintptr(unsafe.Pointer(&b.vec[0]))
+	header.Len = len(b.vec) * wordBytes
+	header.Cap = header.Len
+	return buf
+}
+
+// RawBytes returns the vector as []byte in stored byte order with capacity of the words.
+// returned slice shares memory with b, so changes can be written back to the original region
+// like mmap and the bytes stay in the byte order given at creation.
+func (b *BitSet) RawBytes() []byte {
+	if cap(b.vec) == 0 {
+		return nil
+	}
+	var buf []byte
+	header := (*reflect.SliceHeader)(unsafe.Pointer(&buf))
+	header.Data = uintptr(unsafe.Pointer(&b.vec[:cap(b.vec)][0]))
+	header.Len = len(b.vec) * wordBytes
+	header.Cap = cap(b.vec) * wordBytes
+	return buf
+}
+
+// InRange checks i is under BitLen() and can be set without extending.
+func (b *BitSet) InRange(i uint) bool {
+	return i < b.length
+}
+
 // Get checks the bit is set.
 func (b *BitSet) Get(i uint) bool {
 	idx := i >> log2WordSize
-	if int(idx) >= len(b.vec) {
+	if !b.InRange(i) {
 		return false
 	}
 	if b.swap {
@@ -79,17 +444,25 @@ func (b *BitSet) Get(i uint) bool {
 
 func (c *BitSet) extendVec(size int) bool {
 	if len(c.vec) >= size {
-		// do nothing
+		// padding bits are 0 and become meaningful
 	} else if cap(c.vec) >= size {
+		n := len(c.vec)
 		c.vec = c.vec[:size]
+		// capacity of the original buffer may hold stale bytes.
+		// extended words are 0, so the cached count is kept.
+		for i := n; i < size; i++ {
+			c.vec[i] = 0
+		}
 	} else {
+		if size > maxVecWords {
+			return false
+		}
 		nextcap := size + size
 		if size > 1024 {
 			nextcap = size + size/4
 		}
-		if nextcap <= 0 {
-			// overflow
-			return false
+		if nextcap > maxVecWords {
+			nextcap = maxVecWords
 		}
 		newvec := make([]uint64, size, nextcap)
 		copy(newvec, c.vec)
@@ -97,17 +470,38 @@ func (c *BitSet) extendVec(size int) bool {
 		// remove original reference to GC
 		c.orig = nil
 	}
+	if length := uint(size) * wordBits; c.length < length {
+		c.length = length
+	}
 	return true
 }
 
+// Grow extends the vector and BitLen() to hold at least n bits regardless of extend.
+// extended area is filled with 0.
+// after growing, the vector may not share memory with the original buffer.
+// if n bits are too large to allocate then returns false.
+func (b *BitSet) Grow(n uint) bool {
+	size := n >> log2WordSize
+	if n&(wordBits-1) != 0 {
+		size++
+	}
+	if size > maxVecWords {
+		return false
+	}
+	return b.extendVec(int(size))
+}
+
 // Set sets 1 to bit
 func (b *BitSet) Set(i uint) bool {
 	idx := i >> log2WordSize
-	if int(idx) >= len(b.vec) {
+	if !b.InRange(i) {
 		if !b.extend || !b.extendVec(int(idx +1)) {
 			return false
 		}
 	}
+	if b.cached && !b.Get(i) {
+		b.count++
+	}
 	if b.swap {
 		b.vec[idx] |= 1 << (wordBits - (i & mask00111000) - 8) << (i & mask00000111)
 	} else {
@@ -119,9 +513,12 @@ func (b *BitSet) Set(i uint) bool {
 // Unset sets 0 to bit
 func (b *BitSet) Unset(i uint) bool {
 	idx := i >> log2WordSize
-	if int(idx) >= len(b.vec) {
+	if !b.InRange(i) {
 		return false
 	}
+	if b.cached && b.Get(i) {
+		b.count--
+	}
 	if b.swap {
 		b.vec[idx] &^= 1 << (wordBits - (i & mask00111000) - 8) << (i & mask00000111)
 	} else {
@@ -130,6 +527,87 @@ func (b *BitSet) Unset(i uint) bool {
 	return true
 }
 
+// SetTo sets value to bit
+func (b *BitSet) SetTo(i uint, value bool) bool {
+	idx := i >> log2WordSize
+	if !b.InRange(i) {
+		if !value || !b.extend || !b.extendVec(int(idx+1)) {
+			return false
+		}
+	}
+	var mask uint64
+	if b.swap {
+		mask = 1 << (wordBits - (i & mask00111000) - 8) << (i & mask00000111)
+	} else {
+		mask = 1 << (i & (wordBits - 1))
+	}
+	if b.cached && b.Get(i) != value {
+		if value {
+			b.count++
+		} else {
+			b.count--
+		}
+	}
+	if value {
+		b.vec[idx] |= mask
+	} else {
+		b.vec[idx] &^= mask
+	}
+	return true
+}
+
+// Flip toggles bit
+func (b *BitSet) Flip(i uint) bool {
+	idx := i >> log2WordSize
+	if !b.InRange(i) {
+		if !b.extend || !b.extendVec(int(idx+1)) {
+			return false
+		}
+	}
+	if b.cached {
+		if b.Get(i) {
+			b.count--
+		} else {
+			b.count++
+		}
+	}
+	if b.swap {
+		b.vec[idx] ^= 1 << (wordBits - (i & mask00111000) - 8) << (i & mask00000111)
+	} else {
+		b.vec[idx] ^= 1 << (i & (wordBits - 1))
+	}
+	return true
+}
+
+// MustGet checks the bit is set.
+// it panics if i is out of vector.
+func (b *BitSet) MustGet(i uint) bool {
+	if !b.InRange(i) {
+		b.panicOutOfRange(i)
+	}
+	return b.Get(i)
+}
+
+// MustSet sets 1 to bit.
+// it panics if i is out of vector and the vector is not extended.
+func (b *BitSet) MustSet(i uint) {
+	if !b.Set(i) {
+		b.panicOutOfRange(i)
+	}
+}
+
+// MustUnset sets 0 to bit.
+// it panics if i is out of vector.
+func (b *BitSet) MustUnset(i uint) {
+	if !b.Unset(i) {
+		b.panicOutOfRange(i)
+	}
+}
+
+func (b *BitSet) panicOutOfRange(i uint) {
+	panic(fmt.Sprintf("bitset: index %d out of range with BitLen %d", i, b.BitLen()))
+}
+
 // FindFirstOne returns first 1 bit index and true.
 // if not found then returns false
 func (b *BitSet) FindFirstOne(i uint) (uint, bool) {
@@ -143,7 +621,7 @@ func (b *BitSet) FindFirstOne(i uint) (uint, bool) {
 		if v != 0 {
 			return i + uint(bits.TrailingZeros64(v)), true
 		}
-		for idx++; idx < len(b.vec); idx++ {
+		for idx = skipZeroWords(b.vec, idx+1); idx < len(b.vec); idx++ {
 			if b.vec[idx] != 0 {
 				return uint(idx)*wordBits + uint(bits.TrailingZeros64(swapUint64(b.vec[idx]))), true
 			}
@@ -153,7 +631,7 @@ func (b *BitSet) FindFirstOne(i uint) (uint, bool) {
 		if v != 0 {
 			return i + uint(bits.TrailingZeros64(v)), true
 		}
-		for idx++; idx < len(b.vec); idx++ {
+		for idx = skipZeroWords(b.vec, idx+1); idx < len(b.vec); idx++ {
 			if b.vec[idx] != 0 {
 				return uint(idx)*wordBits + uint(bits.TrailingZeros64(b.vec[idx])), true
 			}
@@ -162,24 +640,60 @@ func (b *BitSet) FindFirstOne(i uint) (uint, bool) {
 	return 0, false
 }
 
-// FindFirstZero returns first 0 bit index and true.
+// skipZeroWords returns the index of the first block of 4 words from idx
+// which has any set bit, or the index of the rest words less than 4.
+func skipZeroWords(vec []uint64, idx int) int {
+	for ; idx+4 <= len(vec); idx += 4 {
+		if vec[idx]|vec[idx+1]|vec[idx+2]|vec[idx+3] != 0 {
+			break
+		}
+	}
+	return idx
+}
+
+// FindFirstOneInRange returns first 1 bit index in [start, end) and true.
 // if not found then returns false
-// TODO: set tail
+func (b *BitSet) FindFirstOneInRange(start, end uint) (uint, bool) {
+	if size := uint(len(b.vec)) * wordBits; end > size {
+		end = size
+	}
+	if start >= end {
+		return 0, false
+	}
+	first, last, head, tail := rangeWords(start, end)
+	for idx := first; idx <= last; idx++ {
+		v := b.vec[idx]
+		if idx == first {
+			v &= b.conv(head)
+		}
+		if idx == last {
+			v &= b.conv(tail)
+		}
+		if v != 0 {
+			return uint(idx)*wordBits + uint(bits.TrailingZeros64(b.conv(v))), true
+		}
+	}
+	return 0, false
+}
+
+// FindFirstZero returns first 0 bit index and true.
+// if not found then returns BitLen() and false.
+// padding bits after BitLen() are not searched.
 func (b *BitSet) FindFirstZero(i uint) (uint, bool) {
-	idx := int(i >> log2WordSize)
-	if idx >= len(b.vec) {
+	if i >= b.length {
 		return i, false
 	}
+	idx := int(i >> log2WordSize)
 	if b.swap {
 		offset := (i & (wordBits - 1))
 		v := swapUint64(b.vec[idx]) >> offset
 		trail := uint(bits.TrailingZeros64(^v))
 		if trail < wordBits-offset {
-			return i + trail, true
+			return b.foundZero(i + trail)
 		}
 		for idx++; idx < len(b.vec); idx++ {
 			if b.vec[idx] != allBits {
-				return uint(idx)*wordBits + uint(bits.TrailingZeros64(^swapUint64(b.vec[idx]))), true
+				return b.foundZero(uint(idx)*wordBits + uint(bits.TrailingZeros64(^swapUint64(b.vec[idx]))))
 			}
 		}
 	} else {
@@ -187,15 +701,69 @@ func (b *BitSet) FindFirstZero(i uint) (uint, bool) {
 		v := b.vec[idx] >> offset
 		trail := uint(bits.TrailingZeros64(^v))
 		if trail < wordBits-offset {
-			return i + trail, true
+			return b.foundZero(i + trail)
 		}
 		for idx++; idx < len(b.vec); idx++ {
 			if b.vec[idx] != allBits {
-				return uint(idx)*wordBits + uint(bits.TrailingZeros64(^b.vec[idx])), true
+				return b.foundZero(uint(idx)*wordBits + uint(bits.TrailingZeros64(^b.vec[idx])))
 			}
 		}
 	}
-	return uint(idx)*wordBits, false
+	return b.length, false
+}
+
+// FindFirstZeroInRange returns first 0 bit index in [start, end) and true.
+// if not found then returns false
+// end is clamped to BitLen().
+func (b *BitSet) FindFirstZeroInRange(start, end uint) (uint, bool) {
+	if end > b.length {
+		end = b.length
+	}
+	if start >= end {
+		return 0, false
+	}
+	first, last, head, tail := rangeWords(start, end)
+	for idx := first; idx <= last; idx++ {
+		v := ^b.vec[idx]
+		if idx == first {
+			v &= b.conv(head)
+		}
+		if idx == last {
+			v &= b.conv(tail)
+		}
+		if v != 0 {
+			return uint(idx)*wordBits + uint(bits.TrailingZeros64(b.conv(v))), true
+		}
+	}
+	return 0, false
+}
+
+// FindZeroRun returns the first index of n consecutive 0 bits under BitLen() and true.
+// if not found then returns false
+func (b *BitSet) FindZeroRun(n uint) (uint, bool) {
+	if n == 0 {
+		return 0, true
+	}
+	for i := uint(0); i+n <= b.length; {
+		start, ok := b.FindFirstZeroInRange(i, b.length)
+		if !ok || start+n > b.length {
+			return 0, false
+		}
+		one, ok := b.FindFirstOneInRange(start, start+n)
+		if !ok {
+			return start, true
+		}
+		i = one + 1
+	}
+	return 0, false
+}
+
+// foundZero checks found 0 bit is not in padding.
+func (b *BitSet) foundZero(i uint) (uint, bool) {
+	if i >= b.length {
+		return b.length, false
+	}
+	return i, true
 }
 
 // FindLastOne returns last 1 bit index and true.
@@ -220,11 +788,558 @@ func (b *BitSet) FindLastOne() (uint, bool) {
 	return 0, false
 }
 
+// Min returns the lowest 1 bit index and true.
+// if no bit is set then returns false
+func (b *BitSet) Min() (uint, bool) {
+	return b.FindFirstOne(0)
+}
+
+// Max returns the highest 1 bit index and true.
+// if no bit is set then returns false
+func (b *BitSet) Max() (uint, bool) {
+	return b.FindLastOne()
+}
+
+// FindLastZero returns last 0 bit index under BitLen() and true.
+// if not found then returns false
+func (b *BitSet) FindLastZero() (uint, bool) {
+	if b.length == 0 {
+		return 0, false
+	}
+	last := int((b.length - 1) >> log2WordSize)
+	for i := last; i >= 0; i-- {
+		v := ^b.conv(b.vec[i])
+		if i == last && b.length&(wordBits-1) != 0 {
+			v &^= allBits << (b.length & (wordBits - 1))
+		}
+		if v != 0 {
+			return uint((i+1)*wordBits - bits.LeadingZeros64(v) - 1), true
+		}
+	}
+	return 0, false
+}
+
+// FindPrevOne returns last 1 bit index at or before i and true.
+// if not found then returns false
+func (b *BitSet) FindPrevOne(i uint) (uint, bool) {
+	if len(b.vec) == 0 {
+		return 0, false
+	}
+	idx := int(i >> log2WordSize)
+	if idx >= len(b.vec) {
+		return b.FindLastOne()
+	}
+	v := b.conv(b.vec[idx]) & (allBits >> (wordBits - 1 - (i & (wordBits - 1))))
+	for {
+		if v != 0 {
+			return uint((idx+1)*wordBits - bits.LeadingZeros64(v) - 1), true
+		}
+		if idx == 0 {
+			return 0, false
+		}
+		idx--
+		v = b.vec[idx]
+		if v != 0 {
+			v = b.conv(v)
+		}
+	}
+}
+
 // Count returns the number of set bit
+// popcount does not depend on byte order, so it skips swapping.
+// if count cache is enabled then returns the cached count.
 func (b *BitSet) Count() uint {
+	if b.cached {
+		return b.count
+	}
+	return countWords(b.vec)
+}
+
+func countWords(vec []uint64) uint {
 	var cnt uint
-	for _, v := range b.vec {
+	for _, v := range vec {
 		cnt += uint(bits.OnesCount64(v))
 	}
 	return cnt
 }
+
+// EnableCountCache makes Count return the number of set bits maintained by modifications.
+// writes through memory shared with the vector (Bytes, RawBytes, the buffer given to New, SubSlice)
+// and atomic operations are not reflected, so call EnableCountCache again to recount.
+func (b *BitSet) EnableCountCache() {
+	b.cached = true
+	b.count = countWords(b.vec)
+}
+
+// DisableCountCache stops maintaining the number of set bits.
+func (b *BitSet) DisableCountCache() {
+	b.cached = false
+	b.count = 0
+}
+
+// recount recounts the cached number of set bits after bulk modification.
+func (b *BitSet) recount() {
+	if b.cached {
+		b.count = countWords(b.vec)
+	}
+}
+
+// CountWords returns the number of set bit of each word.
+// bits.OnesCount64 is POPCNT where the CPU has it and SWAR bit counting otherwise,
+// and the count does not depend on byte order in both ways.
+func (b *BitSet) CountWords() []uint {
+	counts := make([]uint, len(b.vec))
+	for i, v := range b.vec {
+		counts[i] = uint(bits.OnesCount64(v))
+	}
+	return counts
+}
+
+// BlockCounts returns the number of set bit of each block of blockBits bits in [0, BitLen()).
+// the last block may be shorter than blockBits.
+// if blockBits is not multiple of 64 then returns ErrInvalidLength.
+func (b *BitSet) BlockCounts(blockBits uint) ([]uint, error) {
+	if blockBits == 0 || blockBits%wordBits != 0 {
+		return nil, ErrInvalidLength
+	}
+	counts := make([]uint, 0, (b.length+blockBits-1)/blockBits)
+	for start := uint(0); start < b.length; start += blockBits {
+		end := start + blockBits
+		if end > b.length {
+			end = b.length
+		}
+		counts = append(counts, b.CountRange(start, end))
+	}
+	return counts, nil
+}
+
+// Density returns the ratio of set bits in [0, BitLen()) in [0, 1].
+// if BitLen() is 0 then returns 0.
+func (b *BitSet) Density() float64 {
+	if b.length == 0 {
+		return 0
+	}
+	return float64(b.CountRange(0, b.length)) / float64(b.length)
+}
+
+// rangeWords returns the first and last word index of [start, end)
+// and the masks of them in host order. end must be greater than start.
+func rangeWords(start, end uint) (first, last int, head, tail uint64) {
+	first, last = int(start>>log2WordSize), int((end-1)>>log2WordSize)
+	head = allBits << (start & (wordBits - 1))
+	tail = allBits >> (wordBits - 1 - ((end - 1) & (wordBits - 1)))
+	return first, last, head, tail
+}
+
+// CountRange returns the number of set bit in [start, end).
+// end is clamped to the size of vector.
+func (b *BitSet) CountRange(start, end uint) uint {
+	if size := uint(len(b.vec)) * wordBits; end > size {
+		end = size
+	}
+	if start >= end {
+		return 0
+	}
+	first, last, head, tail := rangeWords(start, end)
+	if first == last {
+		return uint(bits.OnesCount64(b.vec[first] & b.conv(head&tail)))
+	}
+	cnt := uint(bits.OnesCount64(b.vec[first] & b.conv(head)))
+	for _, v := range b.vec[first+1 : last] {
+		cnt += uint(bits.OnesCount64(v))
+	}
+	return cnt + uint(bits.OnesCount64(b.vec[last]&b.conv(tail)))
+}
+
+// SetRange sets 1 to bits in [start, end)
+func (b *BitSet) SetRange(start, end uint) bool {
+	if start >= end {
+		return true
+	}
+	if end > b.length {
+		if !b.extend || !b.extendVec(int((end-1)>>log2WordSize+1)) {
+			return false
+		}
+	}
+	if b.cached {
+		b.count += end - start - b.CountRange(start, end)
+	}
+	first, last, head, tail := rangeWords(start, end)
+	if first == last {
+		b.vec[first] |= b.conv(head & tail)
+		return true
+	}
+	b.vec[first] |= b.conv(head)
+	for i := first + 1; i < last; i++ {
+		b.vec[i] = allBits
+	}
+	b.vec[last] |= b.conv(tail)
+	return true
+}
+
+// UnsetRange sets 0 to bits in [start, end).
+// if end is out of BitLen() then returns false without any change.
+func (b *BitSet) UnsetRange(start, end uint) bool {
+	if start >= end {
+		return true
+	}
+	if end > b.length {
+		return false
+	}
+	if b.cached {
+		b.count -= b.CountRange(start, end)
+	}
+	first, last, head, tail := rangeWords(start, end)
+	if first == last {
+		b.vec[first] &^= b.conv(head & tail)
+		return true
+	}
+	b.vec[first] &^= b.conv(head)
+	for i := first + 1; i < last; i++ {
+		b.vec[i] = 0
+	}
+	b.vec[last] &^= b.conv(tail)
+	return true
+}
+
+// SetFrom sets 1 to bits in [start, BitLen()).
+func (b *BitSet) SetFrom(start uint) {
+	b.SetRange(start, b.length)
+}
+
+// UnsetFrom sets 0 to bits in [start, BitLen()).
+func (b *BitSet) UnsetFrom(start uint) {
+	b.UnsetRange(start, b.length)
+}
+
+// Allocate sets 1 to the first n consecutive 0 bits under BitLen() and returns the first index and true.
+// if not found then returns false without any change.
+func (b *BitSet) Allocate(n uint) (uint, bool) {
+	start, ok := b.FindZeroRun(n)
+	if !ok {
+		return 0, false
+	}
+	b.SetRange(start, start+n)
+	return start, true
+}
+
+// Free sets 0 to n bits from start allocated by Allocate.
+// if the range is out of BitLen() then returns false without any change.
+func (b *BitSet) Free(start, n uint) bool {
+	if start+n > b.length || start+n < start {
+		return false
+	}
+	return b.UnsetRange(start, start+n)
+}
+
+// AllSetInRange checks all bits in [start, end) are set.
+// end is clamped to BitLen() and empty range returns true.
+func (b *BitSet) AllSetInRange(start, end uint) bool {
+	if end > b.length {
+		end = b.length
+	}
+	if start >= end {
+		return true
+	}
+	first, last, head, tail := rangeWords(start, end)
+	if first == last {
+		mask := b.conv(head & tail)
+		return b.vec[first]&mask == mask
+	}
+	if mask := b.conv(head); b.vec[first]&mask != mask {
+		return false
+	}
+	for _, v := range b.vec[first+1 : last] {
+		if v != allBits {
+			return false
+		}
+	}
+	mask := b.conv(tail)
+	return b.vec[last]&mask == mask
+}
+
+// AnySetInRange checks any bit in [start, end) is set.
+// end is clamped to BitLen() and empty range returns false.
+func (b *BitSet) AnySetInRange(start, end uint) bool {
+	if end > b.length {
+		end = b.length
+	}
+	if start >= end {
+		return false
+	}
+	first, last, head, tail := rangeWords(start, end)
+	if first == last {
+		return b.vec[first]&b.conv(head&tail) != 0
+	}
+	if b.vec[first]&b.conv(head) != 0 {
+		return true
+	}
+	for _, v := range b.vec[first+1 : last] {
+		if v != 0 {
+			return true
+		}
+	}
+	return b.vec[last]&b.conv(tail) != 0
+}
+
+// FlipRange toggles bits in [start, end)
+func (b *BitSet) FlipRange(start, end uint) bool {
+	if start >= end {
+		return true
+	}
+	if end > b.length {
+		if !b.extend || !b.extendVec(int((end-1)>>log2WordSize+1)) {
+			return false
+		}
+	}
+	if b.cached {
+		ones := b.CountRange(start, end)
+		b.count = b.count - ones + (end - start - ones)
+	}
+	first, last, head, tail := rangeWords(start, end)
+	if first == last {
+		b.vec[first] ^= b.conv(head & tail)
+		return true
+	}
+	b.vec[first] ^= b.conv(head)
+	for i := first + 1; i < last; i++ {
+		b.vec[i] ^= allBits
+	}
+	b.vec[last] ^= b.conv(tail)
+	return true
+}
+
+// SetAll sets 1 to all bits under BitLen().
+// all 1 word is the same in any byte order, so it skips swapping.
+func (b *BitSet) SetAll() {
+	for i := range b.vec {
+		b.vec[i] = allBits
+	}
+	b.clearPadding()
+	b.recount()
+}
+
+// UnsetAll sets 0 to all bits.
+// all 0 word is the same in any byte order, so it skips swapping.
+func (b *BitSet) UnsetAll() {
+	for i := range b.vec {
+		b.vec[i] = 0
+	}
+	b.recount()
+}
+
+// String returns set bit indexes like "{0,3,64}" for debugging.
+// too many bits are omitted with "...".
+func (b *BitSet) String() string {
+	var (
+		buf bytes.Buffer
+		n   int
+	)
+	buf.WriteByte('{')
+	b.ForEachSet(func(i uint) bool {
+		if n > 0 {
+			buf.WriteByte(',')
+		}
+		if n == maxStringBits {
+			buf.WriteString("...")
+			return false
+		}
+		buf.WriteString(strconv.FormatUint(uint64(i), 10))
+		n++
+		return true
+	})
+	buf.WriteByte('}')
+	return buf.String()
+}
+
+// ParseBits create *BitSet from s of '0' and '1' in LSB first order,
+// s[0] is bit 0 and BitLen() is the number of bits. ' ' and '_' are ignored as separators.
+// if s has other characters then returns ErrInvalidFormat.
+func ParseBits(s string, order binary.ByteOrder) (*BitSet, error) {
+	if err := checkOrder(order); err != nil {
+		return nil, err
+	}
+	var length uint
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '0', '1':
+			length++
+		case ' ', '_':
+		default:
+			return nil, ErrInvalidFormat
+		}
+	}
+	nb := &BitSet{
+		vec:    make([]uint64, (length+wordBits-1)/wordBits),
+		swap:   order != hostEndian,
+		length: length,
+	}
+	var n uint
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '1':
+			nb.vec[n>>log2WordSize] |= nb.bitMask(n)
+			n++
+		case '0':
+			n++
+		}
+	}
+	return nb, nil
+}
+
+// Bits returns bits in [0, BitLen()) as '0' and '1' in LSB first order.
+// it is parsed back by ParseBits.
+func (b *BitSet) Bits() string {
+	buf := make([]byte, b.length)
+	for i := range buf {
+		buf[i] = '0'
+	}
+	b.ForEachSet(func(i uint) bool {
+		if i >= b.length {
+			return false
+		}
+		buf[i] = '1'
+		return true
+	})
+	return string(buf)
+}
+
+// Any checks any bit is set.
+// padding bits after BitLen() are ignored.
+// whole words are checked without swapping because all 0 and all 1 words
+// are the same in any byte order.
+func (b *BitSet) Any() bool {
+	n := int(b.length >> log2WordSize)
+	for _, v := range b.vec[:n] {
+		if v != 0 {
+			return true
+		}
+	}
+	if tail := b.length & (wordBits - 1); tail != 0 {
+		return b.vec[n]&b.conv(allBits>>(wordBits-tail)) != 0
+	}
+	return false
+}
+
+// None checks no bit is set.
+// padding bits after BitLen() are ignored.
+func (b *BitSet) None() bool {
+	return !b.Any()
+}
+
+// All checks all bits are set.
+// padding bits after BitLen() are ignored.
+func (b *BitSet) All() bool {
+	n := int(b.length >> log2WordSize)
+	for _, v := range b.vec[:n] {
+		if v != allBits {
+			return false
+		}
+	}
+	if tail := b.length & (wordBits - 1); tail != 0 {
+		mask := b.conv(allBits >> (wordBits - tail))
+		return b.vec[n]&mask == mask
+	}
+	return true
+}
+
+// Complement inverts all bits.
+// not operation is not affected by byte order, so it skips swapping.
+// padding bits after BitLen() are kept 0.
+func (b *BitSet) Complement() {
+	for i, v := range b.vec {
+		b.vec[i] = ^v
+	}
+	b.clearPadding()
+	b.recount()
+}
+
+// ShiftLeft moves bits toward higher index by n and fills 0.
+// bits moved over BitLen() are dropped.
+func (b *BitSet) ShiftLeft(n uint) {
+	if n >= uint(len(b.vec))*wordBits {
+		b.UnsetAll()
+		return
+	}
+	words, offset := int(n>>log2WordSize), n&(wordBits-1)
+	// each word is swapped once when read and once when written
+	hi := b.conv(b.vec[len(b.vec)-1-words])
+	for i := len(b.vec) - 1; i > words; i-- {
+		lo := b.conv(b.vec[i-words-1])
+		b.vec[i] = b.conv(hi<<offset | lo>>(wordBits-offset))
+		hi = lo
+	}
+	b.vec[words] = b.conv(hi << offset)
+	for i := 0; i < words; i++ {
+		b.vec[i] = 0
+	}
+	b.clearPadding()
+	b.recount()
+}
+
+// ShiftRight moves bits toward lower index by n and fills 0.
+// bits moved under index 0 are dropped.
+func (b *BitSet) ShiftRight(n uint) {
+	if n >= uint(len(b.vec))*wordBits {
+		b.UnsetAll()
+		return
+	}
+	words, offset := int(n>>log2WordSize), n&(wordBits-1)
+	last := len(b.vec) - 1 - words
+	// each word is swapped once when read and once when written
+	lo := b.conv(b.vec[words])
+	for i := 0; i < last; i++ {
+		hi := b.conv(b.vec[i+words+1])
+		b.vec[i] = b.conv(lo>>offset | hi<<(wordBits-offset))
+		lo = hi
+	}
+	b.vec[last] = b.conv(lo >> offset)
+	for i := last + 1; i < len(b.vec); i++ {
+		b.vec[i] = 0
+	}
+	b.recount()
+}
+
+// Reverse reverses the order of bits in [0, BitLen()), bit i moves to BitLen() - 1 - i.
+func (b *BitSet) Reverse() {
+	for i, j := 0, len(b.vec)-1; i <= j; i, j = i+1, j-1 {
+		// reversing bits of host words is independent from stored byte order
+		lo, hi := bits.Reverse64(b.conv(b.vec[i])), bits.Reverse64(b.conv(b.vec[j]))
+		b.vec[i], b.vec[j] = b.conv(hi), b.conv(lo)
+	}
+	b.ShiftRight(uint(len(b.vec))*wordBits - b.length)
+}
+
+// RotateLeft moves bits in [0, BitLen()) toward higher index by n circularly.
+// bits moved over BitLen() come back from index 0.
+func (b *BitSet) RotateLeft(n uint) {
+	if b.length == 0 || n%b.length == 0 {
+		return
+	}
+	n %= b.length
+	wrapped := b.Clone()
+	wrapped.ShiftRight(b.length - n)
+	b.ShiftLeft(n)
+	b.Or(wrapped)
+}
+
+// LeadingZeros returns the number of 0 bits from index 0 to the first 1 bit.
+// if no bit is set then returns BitLen().
+func (b *BitSet) LeadingZeros() uint {
+	if i, ok := b.FindFirstOne(0); ok && i < b.length {
+		return i
+	}
+	return b.length
+}
+
+// TrailingZeros returns the number of 0 bits after the last 1 bit to BitLen().
+// if no bit is set then returns BitLen().
+func (b *BitSet) TrailingZeros() uint {
+	if b.length == 0 {
+		return 0
+	}
+	if i, ok := b.FindPrevOne(b.length - 1); ok {
+		return b.length - 1 - i
+	}
+	return b.length
+}
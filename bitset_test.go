@@ -1,6 +1,10 @@
 package bitset
 
 import (
+	"bytes"
+	"fmt"
+	"math/bits"
+	"strings"
 	"testing"
 	"encoding/binary"
 )
@@ -9,6 +13,14 @@ var (
 	endians = []binary.ByteOrder{binary.LittleEndian, binary.BigEndian}
 )
 
+func TestSwapUint64(t *testing.T) {
+	for _, v := range []uint64{0, 1, 0x0011223344556677, allBits, 1 << 63} {
+		if swapUint64(v) != swapUint64Masks(v) {
+			t.Errorf("swap(%#x) == %#x, expected %#x", v, swapUint64(v), swapUint64Masks(v))
+		}
+	}
+}
+
 func TestNew(t *testing.T) {
 	tests := map[string]struct {
 		size  int
@@ -31,6 +43,18 @@ func TestNew(t *testing.T) {
 	}
 }
 
+func TestNew_Unaligned(t *testing.T) {
+	buf := make([]byte, 8*4)
+	for _, offset := range []int{1, 3, 7} {
+		if b, err := New(buf[offset:offset+8*3], binary.LittleEndian, false); err != ErrUnalignedBuffer {
+			t.Errorf("offset %v b : %v, err : %v, expected err : %v", offset, b, err, ErrUnalignedBuffer)
+		}
+	}
+	if _, err := New(buf[8:], binary.LittleEndian, false); err != nil {
+		t.Errorf("failed to create bit vec from aligned buffer %v", err)
+	}
+}
+
 func TestBitVec_Set_Unset(t *testing.T) {
 	t.Run("set and unset in LittleEndian", func(t *testing.T) {
 		buf := make([]byte, 8*10)
@@ -156,7 +180,98 @@ func TestBitVec_Set_Unset(t *testing.T) {
 
 }
 
-func TestBitVec_Get(t *testing.T) {
+func TestNewCopy(t *testing.T) {
+	tests := map[string]struct {
+		size  int
+		order binary.ByteOrder
+		err   error
+	}{
+		"should create length : 0":             {size: 0, order: binary.LittleEndian, err: nil},
+		"should create length : 1024":          {size: 8 * 1024, order: binary.LittleEndian, err: nil},
+		"should be big endian":                 {size: 8 * 1024, order: binary.BigEndian, err: nil},
+		"length of buffer must be N * 8 bytes": {size: 100, order: binary.LittleEndian, err: ErrInvalidLength},
+		"unsupported endian nil":               {size: 1024, order: nil, err: ErrInvalidEndianness},
+	}
+	for name, v := range tests {
+		b, err := NewCopy(make([]byte, v.size), v.order, false)
+		if err != v.err {
+			t.Errorf("%v b : %v, err : %v, expected err : %v", name, b, err, v.err)
+		}
+	}
+
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			buf := make([]byte, 8*3+1)[1:]
+			orig, err := NewCopy(buf, endian, false)
+			if err != nil {
+				t.Fatalf("failed to create bit vec from unaligned buffer %v", err)
+			}
+			if !orig.Set(3) || !orig.Set(127) {
+				t.Errorf("failed to set")
+			}
+			copy(buf, orig.Bytes())
+			if !orig.Unset(3) {
+				t.Errorf("failed to unset")
+			}
+			b, err := NewCopy(buf, endian, false)
+			if err != nil {
+				t.Fatalf("failed to create bit vec %v", err)
+			}
+			assertBits(t, b, []uint{3, 127})
+			if !b.Set(10) || buf[1]|buf[6] != 0 {
+				t.Errorf("vector shares memory with buffer")
+			}
+		})
+	}
+}
+
+func TestNewPadded(t *testing.T) {
+	if _, err := NewPadded(make([]byte, 100), nil, false); err != ErrInvalidEndianness {
+		t.Errorf("err : %v, expected err : %v", err, ErrInvalidEndianness)
+	}
+	tests := map[string]struct {
+		order binary.ByteOrder
+		buf   []byte
+		set   []uint
+	}{
+		"LittleEndian": {
+			order: binary.LittleEndian,
+			buf:   []byte{1, 0, 0, 0, 0, 0, 0, 0x80, 2, 0, 0x80},
+			set:   []uint{0, 63, 65, 87},
+		},
+		"BigEndian": {
+			order: binary.BigEndian,
+			buf:   []byte{0x80, 0, 0, 0, 0, 0, 0, 1, 0x80, 0, 2},
+			set:   []uint{0, 63, 65, 87},
+		},
+	}
+	for name, test := range tests {
+		b, err := NewPadded(test.buf, test.order, false)
+		if err != nil {
+			t.Fatalf("%v : failed to create bit vec %v", name, err)
+		}
+		if b.BitLen() != 11*8 || b.WordCount() != 2 {
+			t.Errorf("%v : bit len == %v, word count == %v", name, b.BitLen(), b.WordCount())
+		}
+		assertBits(t, b, test.set)
+		if !b.SetRange(0, 11*8) {
+			t.Errorf("%v : failed to set range", name)
+		}
+		if result, ok := b.FindFirstZero(0); ok {
+			t.Errorf("%v : unexpectedly found zero in padding %v", name, result)
+		}
+	}
+
+	b, err := NewPadded(make([]byte, 16), binary.BigEndian, false)
+	if err != nil || b.BitLen() != 16*8 || b.WordCount() != 2 {
+		t.Errorf("failed to create bit vec from aligned length err : %v", err)
+	}
+}
+
+func TestNewFromUint64(t *testing.T) {
+	if _, err := NewFromUint64(make([]uint64, 3), nil, false); err != ErrInvalidEndianness {
+		t.Errorf("err : %v, expected err : %v", err, ErrInvalidEndianness)
+	}
 	for _, endian := range endians {
 		t.Run(endian.String(), func(t *testing.T) {
 			buf := make([]byte, 8*3)
@@ -170,185 +285,152 @@ func TestBitVec_Get(t *testing.T) {
 					t.Errorf("failed to set %v", v)
 				}
 			}
-			for _, v := range arr {
-				if !b.Get(v) {
-					t.Errorf("failed to test %v not found", v)
-				}
+			words := make([]uint64, 3)
+			for i := range words {
+				words[i] = hostEndian.Uint64(buf[i*8:])
 			}
-			for _, v := range []uint{2, 4, 5, 7, 8, 100, 8*3*8 - 1, 8 * 3 * 8} {
-				if b.Get(v) {
-					t.Errorf("unexpectedly successed to test %v", v)
-				}
+			b2, err := NewFromUint64(words, endian, false)
+			if err != nil {
+				t.Fatalf("failed to create bit vec %v", err)
+			}
+			if !b.Equal(b2) || b2.swap != b.swap {
+				t.Errorf("vector from words does not match")
+			}
+			if !b2.Set(2) || words[0] == hostEndian.Uint64(buf) {
+				t.Errorf("vector does not share memory with words")
 			}
 		})
 	}
 }
 
-func TestBitVec_FindFirstOne(t *testing.T) {
+func TestNewWithLen(t *testing.T) {
+	if _, err := NewWithLen(make([]byte, 16), 16*8+1, binary.BigEndian, false); err != ErrInvalidLength {
+		t.Errorf("err : %v, expected err : %v", err, ErrInvalidLength)
+	}
+	if _, err := NewWithLen(make([]byte, 10), 8, binary.BigEndian, false); err != ErrInvalidLength {
+		t.Errorf("err : %v, expected err : %v", err, ErrInvalidLength)
+	}
+	if _, err := NewWithLen(make([]byte, 16), 8, nil, false); err != ErrInvalidEndianness {
+		t.Errorf("err : %v, expected err : %v", err, ErrInvalidEndianness)
+	}
 	for _, endian := range endians {
 		t.Run(endian.String(), func(t *testing.T) {
-			t.Run("success to find", func(t *testing.T) {
-				buf := make([]byte, 8*3)
-				b, err := New(buf, endian, false)
-				if err != nil {
-					t.Fatalf("failed to create bit vec %v", err)
-				}
-				arr := []uint{0, 1, 3, 6, 10, 64, 127}
-				for _, v := range arr {
-					if !b.Set(v) {
-						t.Errorf("failed to set %v", v)
-					}
-				}
-				var (
-					v uint
-				)
-				for _, expected := range arr {
-					result, ok := b.FindFirstOne(v)
-					if !ok {
-						t.Errorf("failed to find v : %v, result : %v", v, result)
-					}
-					if result != expected {
-						t.Errorf("found value does not match v : %v, result : %v, expected : %v", v, result, expected)
-					}
-					v = result + 1
-				}
-				if result, ok := b.FindFirstOne(v); ok {
-					t.Errorf("unexpectedly found value  v : %v, result : %v", v, result)
-				}
-			})
-
-			t.Run("all bit is 0", func(t *testing.T) {
-				buf := make([]byte, 8*3)
-				b, err := New(buf, endian, false)
-				if err != nil {
-					t.Fatalf("failed to create bit vec %v", err)
-				}
-				if result, ok := b.FindFirstOne(0); ok {
-					t.Errorf("unexpectedly found value result : %v", result)
-				}
-			})
+			buf := make([]byte, 8*2)
+			b, err := NewWithLen(buf, 100, endian, false)
+			if err != nil {
+				t.Fatalf("failed to create bit vec %v", err)
+			}
+			if b.BitLen() != 100 || b.WordCount() != 2 {
+				t.Errorf("bit len == %v, word count == %v", b.BitLen(), b.WordCount())
+			}
+			if !b.SetRange(0, 100) {
+				t.Fatalf("failed to set range")
+			}
+			if result, ok := b.FindFirstZero(0); ok || result != 100 {
+				t.Errorf("result : %v, %v, expected result : 100, false", result, ok)
+			}
+			if !b.All() {
+				t.Errorf("all bits are expected to be set")
+			}
+			if b.Set(120) || b.Get(120) || b.InRange(120) {
+				t.Errorf("padding bit is set")
+			}
+			if b.vec[0] != allBits {
+				t.Errorf("vector does not share memory with buf")
+			}
 		})
 	}
+	b, err := NewWithLen(nil, 0, binary.LittleEndian, true)
+	if err != nil || b.BitLen() != 0 || !b.None() {
+		t.Errorf("failed to create empty bit vec err : %v", err)
+	}
 }
 
-func TestBitVec_FindFirstZero(t *testing.T) {
+func TestNewWithLen_Padding(t *testing.T) {
 	for _, endian := range endians {
 		t.Run(endian.String(), func(t *testing.T) {
-			t.Run("success to find", func(t *testing.T) {
-				buf := make([]byte, 8*3)
-				b, err := New(buf, endian, false)
-				if err != nil {
-					t.Fatalf("failed to create bit vec %v", err)
-				}
-				for i := 0; i < 8*3*8; i++ {
-					if !b.Set(uint(i)) {
-						t.Errorf("failed to set %v", i)
-					}
-				}
-				arr := []uint{0, 1, 3, 6, 10, 64, 127}
-				for _, v := range arr {
-					if !b.Unset(v) {
-						t.Errorf("failed to unset %v", v)
-					}
-				}
-				var (
-					v uint
-				)
-				for _, expected := range arr {
-					result, ok := b.FindFirstZero(v)
-					if !ok {
-						t.Errorf("failed to find v : %v, result : %v", v, result)
-					}
-					if result != expected {
-						t.Errorf("found value does not match v : %v, result : %v, expected : %v", v, result, expected)
-					}
-					v = result + 1
-				}
-				out := uint(8*3*8+10)
-				result, ok := b.FindFirstZero(out)
-				if ok {
-					t.Errorf("unexpectedly return ok")
-				}
-				if result != out {
-					t.Errorf("out range ffz must be return out value %v, expected %v", result, out)
-				}
-			})
-
-			t.Run("all bit is 1", func(t *testing.T) {
-				buf := make([]byte, 8*3)
-				b, err := New(buf, endian, false)
-				if err != nil {
-					t.Fatalf("failed to create bit vec %v", err)
-				}
-				for i := 0; i < 8*3*8; i++ {
-					if !b.Set(uint(i)) {
-						t.Errorf("failed to set %v", i)
+			buf := []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+			b, err := NewWithLen(buf, 10, endian, false)
+			if err != nil {
+				t.Fatalf("failed to create bit vec %v", err)
+			}
+			if b.Count() != 10 || !b.All() {
+				t.Errorf("dirty padding : count == %v, %v", b.Count(), b)
+			}
+			b.UnsetAll()
+			if b.Set(20) || b.Get(20) || b.InRange(20) {
+				t.Errorf("padding bit 20 is set")
+			}
+			func() {
+				defer func() {
+					if recover() == nil {
+						t.Errorf("must get 20 did not panic")
 					}
-				}
-				result, ok := b.FindFirstZero(0)
-				if ok {
-					t.Errorf("unexpectedly found value result : %v", result)
-				}
-				if result != 8*3*8 {
-					t.Errorf("unexpectedly found value result : %v, expected %v", result, 8*3*8)
-				}
-			})
+				}()
+				b.MustGet(20)
+			}()
+			if b.Any() || b.Count() != 0 || b.Bits() != "0000000000" || b.String() != "{}" {
+				t.Errorf("empty vector : count == %v, bits == %v, string == %v", b.Count(), b.Bits(), b.String())
+			}
+			if result, ok := b.FindLastZero(); !ok || result != 9 {
+				t.Errorf("find last zero == %v, %v, expected 9, true", result, ok)
+			}
+			b.SetAll()
+			if b.Count() != 10 || b.Density() != 1 || b.Validate() != nil {
+				t.Errorf("set all : count == %v, density == %v, %v", b.Count(), b.Density(), b.Validate())
+			}
+			if _, ok := b.FindLastZero(); ok {
+				t.Errorf("zero found in full vector")
+			}
+			b.Complement()
+			if b.Any() || b.Validate() != nil {
+				t.Errorf("complement : %v, %v", b, b.Validate())
+			}
+			b.Set(3)
+			b.Complement()
+			if b.Count() != 9 || b.Get(3) || b.Validate() != nil {
+				t.Errorf("complement : count == %v, %v", b.Count(), b)
+			}
 		})
 	}
 }
 
-func TestBitVec_FindLastOne(t *testing.T) {
+func TestFromIndices(t *testing.T) {
+	if _, err := FromIndices(nil, 100, nil, false); err != ErrInvalidEndianness {
+		t.Errorf("err : %v, expected err : %v", err, ErrInvalidEndianness)
+	}
+	if _, err := FromIndices([]uint{1, 100}, 100, binary.BigEndian, false); err != ErrOutOfRange {
+		t.Errorf("err : %v, expected err : %v", err, ErrOutOfRange)
+	}
+	if bits.UintSize == 64 {
+		for _, bitLen := range []uint{^uint(0) - 1, ^uint(0), ^uint(0) >> 1} {
+			if _, err := FromIndices(nil, bitLen, binary.BigEndian, false); err != ErrInvalidLength {
+				t.Errorf("bit len %v err : %v, expected err : %v", bitLen, err, ErrInvalidLength)
+			}
+		}
+	}
 	for _, endian := range endians {
 		t.Run(endian.String(), func(t *testing.T) {
-			t.Run("success to find", func(t *testing.T) {
-				buf := make([]byte, 8*3)
-				b, err := New(buf, endian, false)
-				if err != nil {
-					t.Fatalf("failed to create bit vec %v", err)
-				}
-
-				tests := []struct {
-					result uint
-					ok     bool
-					set    []uint
-				}{
-					{result: 0, ok: true, set: []uint{0}},
-					{result: 1, ok: true, set: []uint{0, 1}},
-					{result: 1, ok: true, set: []uint{1}},
-					{result: 8, ok: true, set: []uint{0, 8}},
-					{ok: false, set: []uint{}},
-				}
-
-				for _, test := range tests {
-					for _, v := range test.set {
-						if !b.Set(v) {
-							t.Errorf("failed to set %v", v)
-						}
-					}
-
-					result, ok := b.FindLastOne()
-					if ok != test.ok {
-						if ok {
-							t.Errorf("unexpectedly found last one at %v for %v", result, test.set)
-						} else {
-							t.Errorf("not found last one for %v", test.set)
-						}
-					} else if result != test.result {
-						t.Errorf("last one index %v, expected %v for %v", result, test.result, test.set)
-					}
-
-					for _, v := range test.set {
-						if !b.Unset(v) {
-							t.Errorf("failed to unset %v", v)
-						}
-					}
-				}
-			})
+			arr := []uint{0, 1, 3, 6, 10, 63, 64, 99}
+			b, err := FromIndices(arr, 100, endian, false)
+			if err != nil {
+				t.Fatalf("failed to create bit vec %v", err)
+			}
+			if b.BitLen() != 100 || b.WordCount() != 2 {
+				t.Errorf("bit len == %v, word count == %v", b.BitLen(), b.WordCount())
+			}
+			assertBits(t, b, arr)
+			if !b.Equal(newBitSetWith(t, 8*2, endian, arr)) {
+				t.Errorf("vector from indices does not match")
+			}
+			if b2, err := FromIndices(b.SetIndices(), b.BitLen(), endian, false); err != nil || !b2.Equal(b) {
+				t.Errorf("round trip through indices does not match err : %v", err)
+			}
 		})
 	}
 }
 
-func TestBitSet_Count(t *testing.T) {
+func TestBitSet_Clone(t *testing.T) {
 	for _, endian := range endians {
 		t.Run(endian.String(), func(t *testing.T) {
 			buf := make([]byte, 8*3)
@@ -362,8 +444,1884 @@ func TestBitSet_Count(t *testing.T) {
 					t.Errorf("failed to set %v", v)
 				}
 			}
-			if b.Count() != uint(len(arr)) {
-				t.Errorf("count == %v, expected %v", b.Count(), len(arr))
+			c := b.Clone()
+			if c.swap != b.swap {
+				t.Errorf("swap flag is not preserved")
+			}
+			for _, v := range arr {
+				if !c.Get(v) {
+					t.Errorf("cloned vector does not have %v", v)
+				}
+			}
+			if !b.Set(2) || !b.Unset(0) {
+				t.Errorf("failed to modify original vector")
+			}
+			if c.Get(2) || !c.Get(0) {
+				t.Errorf("modification of original vector affects the clone")
+			}
+		})
+	}
+}
+
+func TestBitSet_Shrink(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			arr := []uint{0, 1, 3, 64, 127}
+			b := newBitSetWith(t, 8*4, endian, arr)
+			shrunk := b.Shrink()
+			if shrunk.WordCount() != 2 || shrunk.BitLen() != 128 || shrunk.swap != b.swap {
+				t.Errorf("word count == %v, bit len == %v, swap == %v", shrunk.WordCount(), shrunk.BitLen(), shrunk.swap)
+			}
+			assertBits(t, shrunk, arr)
+			if !shrunk.Set(2) || b.Get(2) {
+				t.Errorf("shrunk vector shares memory with original")
+			}
+			if !b.SetBitLen(100) || b.Shrink().BitLen() != 100 {
+				t.Errorf("bit len is not kept")
+			}
+
+			b.UnsetAll()
+			if shrunk := b.Shrink(); shrunk.WordCount() != 1 || shrunk.BitLen() != 64 {
+				t.Errorf("all 0 vector : word count == %v, bit len == %v", shrunk.WordCount(), shrunk.BitLen())
+			}
+		})
+	}
+}
+
+func TestBitSet_SubSlice(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			buf := make([]byte, 8*4)
+			b, err := New(buf, endian, true)
+			if err != nil {
+				t.Fatalf("failed to create bit vec %v", err)
+			}
+			for _, v := range []uint{0, 64, 130, 200} {
+				if !b.Set(v) {
+					t.Errorf("failed to set %v", v)
+				}
+			}
+			sub, err := b.SubSlice(1, 3)
+			if err != nil {
+				t.Fatalf("failed to create sub slice %v", err)
+			}
+			if sub.BitLen() != 128 || sub.Count() != 2 || !sub.Get(0) || !sub.Get(66) {
+				t.Errorf("sub slice does not match %v", sub)
+			}
+			if !sub.Set(1) || !b.Get(65) {
+				t.Errorf("modification through sub slice is not visible")
+			}
+			if sub.Set(128) || b.Get(192) {
+				t.Errorf("sub slice is extended over wordEnd")
+			}
+			if empty, err := b.SubSlice(4, 4); err != nil || empty.BitLen() != 0 {
+				t.Errorf("failed to create empty sub slice %v", err)
+			}
+			for _, r := range [][2]int{{-1, 1}, {2, 1}, {0, 5}} {
+				if _, err := b.SubSlice(r[0], r[1]); err != ErrOutOfRange {
+					t.Errorf("sub slice %v err : %v, expected err : %v", r, err, ErrOutOfRange)
+				}
+			}
+		})
+	}
+}
+
+func TestBitSet_SetTo(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			buf := make([]byte, 8*3)
+			b, err := New(buf, endian, false)
+			if err != nil {
+				t.Fatalf("failed to create bit vec %v", err)
+			}
+			arr := []uint{0, 1, 3, 6, 10, 64, 127}
+			for _, v := range arr {
+				if !b.SetTo(v, true) {
+					t.Errorf("failed to set %v", v)
+				}
+			}
+			for _, v := range arr {
+				if !b.Get(v) {
+					t.Errorf("failed to test %v not found", v)
+				}
+			}
+			for _, v := range arr[1:] {
+				if !b.SetTo(v, false) {
+					t.Errorf("failed to unset %v", v)
+				}
+			}
+			if !b.Get(0) || b.Count() != 1 {
+				t.Errorf("unexpected bits remain count : %v", b.Count())
+			}
+			if b.SetTo(8*3*8, true) || b.SetTo(8*3*8, false) {
+				t.Errorf("invalid to success to set next to the last bit")
+			}
+		})
+	}
+}
+
+func TestBitSet_Flip(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			buf := make([]byte, 8*3)
+			b, err := New(buf, endian, false)
+			if err != nil {
+				t.Fatalf("failed to create bit vec %v", err)
+			}
+			arr := []uint{0, 1, 3, 6, 10, 64, 127}
+			for _, v := range arr {
+				if !b.Flip(v) {
+					t.Errorf("failed to flip %v", v)
+				}
+			}
+			for _, v := range arr {
+				if !b.Get(v) {
+					t.Errorf("flipped bit %v is not set", v)
+				}
+			}
+			if b.Count() != uint(len(arr)) {
+				t.Errorf("count == %v, expected %v", b.Count(), len(arr))
+			}
+			for _, v := range arr {
+				if !b.Flip(v) {
+					t.Errorf("failed to flip %v", v)
+				}
+			}
+			if b.Count() != 0 {
+				t.Errorf("count == %v after flip twice, expected 0", b.Count())
+			}
+			if b.Flip(8 * 3 * 8) {
+				t.Errorf("invalid to success to flip next to the last bit")
+			}
+		})
+	}
+}
+
+func TestBitSet_BitLen(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			for _, size := range []int{0, 8, 8 * 3} {
+				b, err := New(make([]byte, size), endian, false)
+				if err != nil {
+					t.Fatalf("failed to create bit vec %v", err)
+				}
+				if b.BitLen() != uint(size*8) {
+					t.Errorf("bit len == %v, expected %v", b.BitLen(), size*8)
+				}
+				if size > 0 && !b.Set(b.BitLen()-1) {
+					t.Errorf("failed to set the last bit")
+				}
+				if b.Set(b.BitLen()) {
+					t.Errorf("invalid to success to set next to the last bit")
+				}
+			}
+		})
+	}
+}
+
+func TestBitSet_Word_SetWord(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			buf := make([]byte, 8*3)
+			b, err := New(buf, endian, false)
+			if err != nil {
+				t.Fatalf("failed to create bit vec %v", err)
+			}
+			if b.WordCount() != 3 {
+				t.Errorf("word count == %v, expected %v", b.WordCount(), 3)
+			}
+			for _, v := range []uint{0, 3, 64, 127} {
+				if !b.Set(v) {
+					t.Errorf("failed to set %v", v)
+				}
+			}
+			if w := b.Word(0); w != 1|1<<3 {
+				t.Errorf("word 0 == %#x, expected %#x", w, 1|1<<3)
+			}
+			if w := b.Word(1); w != 1|1<<63 {
+				t.Errorf("word 1 == %#x, expected %#x", w, uint64(1|1<<63))
+			}
+			if w := b.Word(3); w != 0 {
+				t.Errorf("out of range word == %#x, expected 0", w)
+			}
+
+			if !b.SetWord(2, 1<<10|1<<40) {
+				t.Errorf("failed to set word")
+			}
+			if !b.Get(128+10) || !b.Get(128+40) || b.CountRange(128, 192) != 2 {
+				t.Errorf("set word does not match bits")
+			}
+			if endian.Uint64(buf[16:]) != 1<<10|1<<40 {
+				t.Errorf("stored word %v is not %v", buf[16:], endian)
+			}
+			if b.SetWord(3, 1) || b.SetWord(-1, 1) {
+				t.Errorf("invalid to success to set out of range word")
+			}
+		})
+	}
+}
+
+func TestBitSet_ToUint64Slice(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			buf := make([]byte, 8*3)
+			b, err := New(buf, endian, false)
+			if err != nil {
+				t.Fatalf("failed to create bit vec %v", err)
+			}
+			for _, v := range []uint{0, 1, 3, 6, 10, 64, 127, 191} {
+				if !b.Set(v) {
+					t.Errorf("failed to set %v", v)
+				}
+			}
+			words := b.ToUint64Slice()
+			if len(words) != 3 {
+				t.Fatalf("len(words) == %v, expected 3", len(words))
+			}
+			for i := uint(0); i < 8*3*8; i++ {
+				if bit := words[i/64]&(1<<(i%64)) != 0; bit != b.Get(i) {
+					t.Errorf("bit %v of words == %v, expected %v", i, bit, b.Get(i))
+				}
+			}
+			words[0] = 0
+			if !b.Get(0) {
+				t.Errorf("words shares memory with vector")
+			}
+		})
+	}
+}
+
+func TestBitSet_Bytes(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			buf := make([]byte, 8*3)
+			b, err := New(buf, endian, false)
+			if err != nil {
+				t.Fatalf("failed to create bit vec %v", err)
+			}
+			arr := []uint{0, 1, 3, 6, 10, 64, 127}
+			for _, v := range arr {
+				if !b.Set(v) {
+					t.Errorf("failed to set %v", v)
+				}
+			}
+			out := b.Bytes()
+			if len(out) != len(buf) || &out[0] != &buf[0] {
+				t.Fatalf("bytes does not alias the buffer len : %v", len(out))
+			}
+			b2, err := New(append([]byte{}, out...), endian, false)
+			if err != nil {
+				t.Fatalf("failed to create bit vec %v", err)
+			}
+			if !b.Equal(b2) {
+				t.Errorf("re-created vector from bytes does not match")
+			}
+			if b, err := New(nil, endian, false); err != nil || b.Bytes() != nil {
+				t.Errorf("bytes of empty vector must be nil")
+			}
+		})
+	}
+}
+
+func TestBitSet_RawBytes(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			buf := make([]byte, 8*3, 8*5)
+			b, err := New(buf, endian, false)
+			if err != nil {
+				t.Fatalf("failed to create bit vec %v", err)
+			}
+			if !b.Set(0) || !b.Set(127) {
+				t.Errorf("failed to set bits")
+			}
+			out := b.RawBytes()
+			if len(out) != len(buf) || cap(out) != cap(buf) || &out[0] != &buf[0] {
+				t.Fatalf("raw bytes does not alias the buffer len : %v, cap : %v", len(out), cap(out))
+			}
+			if !bytes.Equal(out, b.Bytes()) {
+				t.Errorf("raw bytes %v, expected %v", out, b.Bytes())
+			}
+			if b, err := New(buf[:0], endian, false); err != nil || cap(b.RawBytes()) != cap(buf) {
+				t.Errorf("raw bytes of empty view must keep capacity err : %v", err)
+			}
+			if b, err := New(nil, endian, false); err != nil || b.RawBytes() != nil {
+				t.Errorf("raw bytes of empty vector must be nil")
+			}
+		})
+	}
+}
+
+func TestBitSet_Grow(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			buf := make([]byte, 16)
+			b, err := New(buf, endian, false)
+			if err != nil {
+				t.Fatalf("failed to create bit vec %v", err)
+			}
+			arr := []uint{0, 1, 3, 6, 10, 64, 127}
+			for _, v := range arr {
+				if !b.Set(v) {
+					t.Errorf("failed to set %v", v)
+				}
+			}
+			if !b.Grow(100) || b.BitLen() != 16*8 {
+				t.Errorf("grow to smaller size changes bit len %v", b.BitLen())
+			}
+			if !b.Grow(16*8 + 1) {
+				t.Fatalf("failed to grow")
+			}
+			if b.BitLen() != 3*64 || b.orig != nil {
+				t.Errorf("bit len == %v after grow, expected %v", b.BitLen(), 3*64)
+			}
+			for i := uint(0); i < b.BitLen(); i++ {
+				expected := false
+				for _, v := range arr {
+					expected = expected || v == i
+				}
+				if b.Get(i) != expected {
+					t.Errorf("get(%v) == %v after grow, expected %v", i, b.Get(i), expected)
+				}
+			}
+			if !b.Set(3*64 - 1) {
+				t.Errorf("failed to set the last bit of grown vector")
+			}
+			orig := append([]byte{}, buf...)
+			if !b.Unset(0) || !bytes.Equal(buf, orig) {
+				t.Errorf("grown vector still shares memory with original buffer")
+			}
+		})
+	}
+}
+
+func TestBitSet_Grow_TooLarge(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			b := newBitSetWith(t, 8*2, endian, []uint{3})
+			if b.extendVec(maxVecWords + 1) {
+				t.Errorf("extended over max words")
+			}
+			if bits.UintSize == 64 && (b.Grow(1<<(bits.UintSize-2)) || b.Grow(^uint(0))) {
+				t.Errorf("grew over max words")
+			}
+			if b.BitLen() != 8*2*8 || b.WordCount() != 2 {
+				t.Errorf("bit len == %v, word count == %v", b.BitLen(), b.WordCount())
+			}
+			assertBits(t, b, []uint{3})
+		})
+	}
+}
+
+func TestBitSet_Grow_DirtyCapacity(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			buf := make([]byte, 16)
+			for i := range buf[8:] {
+				buf[8+i] = 0xff
+			}
+			b, err := New(buf[:8], endian, false)
+			if err != nil {
+				t.Fatalf("failed to create bit vec %v", err)
+			}
+			if !b.Set(3) || !b.Grow(128) {
+				t.Fatalf("failed to grow")
+			}
+			if b.BitLen() != 128 || b.WordCount() != 2 {
+				t.Errorf("bit len == %v, word count == %v", b.BitLen(), b.WordCount())
+			}
+			assertBits(t, b, []uint{3})
+			if b.Count() != 1 {
+				t.Errorf("count == %v, expected 1", b.Count())
+			}
+		})
+	}
+}
+
+func TestBitSet_InRange(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			buf := make([]byte, 8*3)
+			b, err := New(buf, endian, false)
+			if err != nil {
+				t.Fatalf("failed to create bit vec %v", err)
+			}
+			for _, i := range []uint{0, 63, 64, 8*3*8 - 1} {
+				if !b.InRange(i) || !b.Set(i) {
+					t.Errorf("%v is not in range", i)
+				}
+			}
+			for _, i := range []uint{8 * 3 * 8, 1 << 31, ^uint(0)} {
+				if b.InRange(i) || b.Set(i) || b.Get(i) {
+					t.Errorf("%v is in range", i)
+				}
+			}
+		})
+	}
+}
+
+func TestBitVec_Get(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			buf := make([]byte, 8*3)
+			b, err := New(buf, endian, false)
+			if err != nil {
+				t.Fatalf("failed to create bit vec %v", err)
+			}
+			arr := []uint{0, 1, 3, 6, 10, 64, 127}
+			for _, v := range arr {
+				if !b.Set(v) {
+					t.Errorf("failed to set %v", v)
+				}
+			}
+			for _, v := range arr {
+				if !b.Get(v) {
+					t.Errorf("failed to test %v not found", v)
+				}
+			}
+			for _, v := range []uint{2, 4, 5, 7, 8, 100, 8*3*8 - 1, 8 * 3 * 8} {
+				if b.Get(v) {
+					t.Errorf("unexpectedly successed to test %v", v)
+				}
+			}
+		})
+	}
+}
+
+func TestBitSet_MustGet_MustSet_MustUnset(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			buf := make([]byte, 16)
+			b, err := New(buf, endian, false)
+			if err != nil {
+				t.Fatalf("failed to create bit vec %v", err)
+			}
+			b.MustSet(127)
+			if !b.MustGet(127) {
+				t.Errorf("failed to set 127")
+			}
+			b.MustUnset(127)
+			if b.MustGet(127) {
+				t.Errorf("failed to unset 127")
+			}
+
+			tests := map[string]func(){
+				"MustGet":   func() { b.MustGet(128) },
+				"MustSet":   func() { b.MustSet(128) },
+				"MustUnset": func() { b.MustUnset(128) },
+			}
+			for name, fn := range tests {
+				func() {
+					defer func() {
+						if r := recover(); r == nil {
+							t.Errorf("%v does not panic", name)
+						} else if !strings.Contains(fmt.Sprint(r), "index 128") || !strings.Contains(fmt.Sprint(r), "BitLen 128") {
+							t.Errorf("%v panics with %v", name, r)
+						}
+					}()
+					fn()
+				}()
+			}
+		})
+	}
+}
+
+func TestBitVec_FindFirstOne(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			t.Run("success to find", func(t *testing.T) {
+				buf := make([]byte, 8*3)
+				b, err := New(buf, endian, false)
+				if err != nil {
+					t.Fatalf("failed to create bit vec %v", err)
+				}
+				arr := []uint{0, 1, 3, 6, 10, 64, 127}
+				for _, v := range arr {
+					if !b.Set(v) {
+						t.Errorf("failed to set %v", v)
+					}
+				}
+				var (
+					v uint
+				)
+				for _, expected := range arr {
+					result, ok := b.FindFirstOne(v)
+					if !ok {
+						t.Errorf("failed to find v : %v, result : %v", v, result)
+					}
+					if result != expected {
+						t.Errorf("found value does not match v : %v, result : %v, expected : %v", v, result, expected)
+					}
+					v = result + 1
+				}
+				if result, ok := b.FindFirstOne(v); ok {
+					t.Errorf("unexpectedly found value  v : %v, result : %v", v, result)
+				}
+			})
+
+			t.Run("all bit is 0", func(t *testing.T) {
+				buf := make([]byte, 8*3)
+				b, err := New(buf, endian, false)
+				if err != nil {
+					t.Fatalf("failed to create bit vec %v", err)
+				}
+				if result, ok := b.FindFirstOne(0); ok {
+					t.Errorf("unexpectedly found value result : %v", result)
+				}
+			})
+		})
+	}
+}
+
+func TestBitSet_FindFirstOne_Long(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			const size = 8 * 11
+			for i := uint(0); i < size*8; i += 7 {
+				buf := make([]byte, size)
+				b, err := New(buf, endian, false)
+				if err != nil {
+					t.Fatalf("failed to create bit vec %v", err)
+				}
+				if !b.Set(i) {
+					t.Errorf("failed to set %v", i)
+				}
+				for _, from := range []uint{0, 1, 65} {
+					result, ok := b.FindFirstOne(from)
+					if expected := from <= i; ok != expected || (ok && result != i) {
+						t.Errorf("find from %v == %v, %v, expected %v", from, result, ok, i)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestBitSet_FindFirstOneInRange(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			buf := make([]byte, 8*3)
+			b, err := New(buf, endian, false)
+			if err != nil {
+				t.Fatalf("failed to create bit vec %v", err)
+			}
+			for _, v := range []uint{3, 10, 64, 127, 191} {
+				if !b.Set(v) {
+					t.Errorf("failed to set %v", v)
+				}
+			}
+			tests := []struct {
+				start, end uint
+				result     uint
+				ok         bool
+			}{
+				{start: 0, end: 3, ok: false},
+				{start: 0, end: 4, result: 3, ok: true},
+				{start: 4, end: 10, ok: false},
+				{start: 4, end: 11, result: 10, ok: true},
+				{start: 11, end: 200, result: 64, ok: true},
+				{start: 65, end: 127, ok: false},
+				{start: 65, end: 128, result: 127, ok: true},
+				{start: 128, end: 1000, result: 191, ok: true},
+				{start: 10, end: 10, ok: false},
+				{start: 1000, end: 2000, ok: false},
+			}
+			for _, test := range tests {
+				result, ok := b.FindFirstOneInRange(test.start, test.end)
+				if ok != test.ok || (ok && result != test.result) {
+					t.Errorf("find [%v, %v) == %v, %v, expected %v, %v", test.start, test.end, result, ok, test.result, test.ok)
+				}
+			}
+		})
+	}
+}
+
+func TestBitVec_FindFirstZero(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			t.Run("success to find", func(t *testing.T) {
+				buf := make([]byte, 8*3)
+				b, err := New(buf, endian, false)
+				if err != nil {
+					t.Fatalf("failed to create bit vec %v", err)
+				}
+				for i := 0; i < 8*3*8; i++ {
+					if !b.Set(uint(i)) {
+						t.Errorf("failed to set %v", i)
+					}
+				}
+				arr := []uint{0, 1, 3, 6, 10, 64, 127}
+				for _, v := range arr {
+					if !b.Unset(v) {
+						t.Errorf("failed to unset %v", v)
+					}
+				}
+				var (
+					v uint
+				)
+				for _, expected := range arr {
+					result, ok := b.FindFirstZero(v)
+					if !ok {
+						t.Errorf("failed to find v : %v, result : %v", v, result)
+					}
+					if result != expected {
+						t.Errorf("found value does not match v : %v, result : %v, expected : %v", v, result, expected)
+					}
+					v = result + 1
+				}
+				out := uint(8*3*8+10)
+				result, ok := b.FindFirstZero(out)
+				if ok {
+					t.Errorf("unexpectedly return ok")
+				}
+				if result != out {
+					t.Errorf("out range ffz must be return out value %v, expected %v", result, out)
+				}
+			})
+
+			t.Run("all bit is 1", func(t *testing.T) {
+				buf := make([]byte, 8*3)
+				b, err := New(buf, endian, false)
+				if err != nil {
+					t.Fatalf("failed to create bit vec %v", err)
+				}
+				for i := 0; i < 8*3*8; i++ {
+					if !b.Set(uint(i)) {
+						t.Errorf("failed to set %v", i)
+					}
+				}
+				result, ok := b.FindFirstZero(0)
+				if ok {
+					t.Errorf("unexpectedly found value result : %v", result)
+				}
+				if result != 8*3*8 {
+					t.Errorf("unexpectedly found value result : %v, expected %v", result, 8*3*8)
+				}
+			})
+		})
+	}
+}
+
+func TestBitSet_FindFirstZero_BitLen(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			buf := make([]byte, 8*3)
+			b, err := New(buf, endian, false)
+			if err != nil {
+				t.Fatalf("failed to create bit vec %v", err)
+			}
+			if b.SetBitLen(8*3*8 + 1) {
+				t.Errorf("invalid to success to set bit len over the vector")
+			}
+			if !b.SetBitLen(100) || b.BitLen() != 100 {
+				t.Fatalf("failed to set bit len")
+			}
+			for i := 0; i < 100; i++ {
+				if !b.Set(uint(i)) {
+					t.Errorf("failed to set %v", i)
+				}
+			}
+			if result, ok := b.FindFirstZero(0); ok || result != 100 {
+				t.Errorf("ffz == %v, %v, expected 100, false", result, ok)
+			}
+			if result, ok := b.FindFirstZero(120); ok || result != 120 {
+				t.Errorf("ffz out of bit len == %v, %v, expected 120, false", result, ok)
+			}
+			if !b.Unset(99) {
+				t.Errorf("failed to unset 99")
+			}
+			if result, ok := b.FindFirstZero(64); !ok || result != 99 {
+				t.Errorf("ffz == %v, %v, expected 99, true", result, ok)
+			}
+		})
+	}
+}
+
+func TestBitSet_FindFirstZeroInRange(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			buf := make([]byte, 8*3)
+			b, err := New(buf, endian, false)
+			if err != nil {
+				t.Fatalf("failed to create bit vec %v", err)
+			}
+			b.SetAll()
+			for _, v := range []uint{3, 10, 64, 127, 180} {
+				if !b.Unset(v) {
+					t.Errorf("failed to unset %v", v)
+				}
+			}
+			if !b.SetBitLen(170) {
+				t.Fatalf("failed to set bit len")
+			}
+			tests := []struct {
+				start, end uint
+				result     uint
+				ok         bool
+			}{
+				{start: 0, end: 3, ok: false},
+				{start: 0, end: 4, result: 3, ok: true},
+				{start: 4, end: 10, ok: false},
+				{start: 4, end: 11, result: 10, ok: true},
+				{start: 10, end: 10, ok: false},
+				{start: 11, end: 200, result: 64, ok: true},
+				{start: 65, end: 127, ok: false},
+				{start: 65, end: 128, result: 127, ok: true},
+				{start: 128, end: 1000, ok: false},
+			}
+			for _, test := range tests {
+				result, ok := b.FindFirstZeroInRange(test.start, test.end)
+				if ok != test.ok || (ok && result != test.result) {
+					t.Errorf("find [%v, %v) == %v, %v, expected %v, %v", test.start, test.end, result, ok, test.result, test.ok)
+				}
+			}
+		})
+	}
+}
+
+func TestBitSet_FindZeroRun(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			b := newBitSetWith(t, 8*4, endian, nil)
+			b.SetRange(0, 3)
+			b.SetRange(5, 60)
+			b.SetRange(70, 140)
+			b.SetRange(200, 256)
+			if !b.SetBitLen(220) {
+				t.Fatalf("failed to set bit len")
+			}
+			tests := []struct {
+				n        uint
+				expected uint
+				ok       bool
+			}{
+				{n: 0, expected: 0, ok: true},
+				{n: 1, expected: 3, ok: true},
+				{n: 2, expected: 3, ok: true},
+				{n: 3, expected: 60, ok: true},
+				{n: 10, expected: 60, ok: true},
+				{n: 11, expected: 140, ok: true},
+				{n: 60, expected: 140, ok: true},
+				{n: 61, expected: 0, ok: false},
+			}
+			for _, test := range tests {
+				result, ok := b.FindZeroRun(test.n)
+				if ok != test.ok || ok && result != test.expected {
+					t.Errorf("find zero run %v == %v, %v, expected %v, %v", test.n, result, ok, test.expected, test.ok)
+				}
+			}
+			b.UnsetRange(200, 220)
+			if result, ok := b.FindZeroRun(80); !ok || result != 140 {
+				t.Errorf("find zero run 80 == %v, %v, expected 140, true", result, ok)
+			}
+			if result, ok := b.FindZeroRun(81); ok {
+				t.Errorf("unexpectedly found zero run %v over bit len", result)
+			}
+		})
+	}
+}
+
+func TestBitVec_FindLastOne(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			t.Run("success to find", func(t *testing.T) {
+				buf := make([]byte, 8*3)
+				b, err := New(buf, endian, false)
+				if err != nil {
+					t.Fatalf("failed to create bit vec %v", err)
+				}
+
+				tests := []struct {
+					result uint
+					ok     bool
+					set    []uint
+				}{
+					{result: 0, ok: true, set: []uint{0}},
+					{result: 1, ok: true, set: []uint{0, 1}},
+					{result: 1, ok: true, set: []uint{1}},
+					{result: 8, ok: true, set: []uint{0, 8}},
+					{ok: false, set: []uint{}},
+				}
+
+				for _, test := range tests {
+					for _, v := range test.set {
+						if !b.Set(v) {
+							t.Errorf("failed to set %v", v)
+						}
+					}
+
+					result, ok := b.FindLastOne()
+					if ok != test.ok {
+						if ok {
+							t.Errorf("unexpectedly found last one at %v for %v", result, test.set)
+						} else {
+							t.Errorf("not found last one for %v", test.set)
+						}
+					} else if result != test.result {
+						t.Errorf("last one index %v, expected %v for %v", result, test.result, test.set)
+					}
+
+					for _, v := range test.set {
+						if !b.Unset(v) {
+							t.Errorf("failed to unset %v", v)
+						}
+					}
+				}
+			})
+		})
+	}
+}
+
+func TestBitSet_Min_Max(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			b := newBitSetWith(t, 8*3, endian, nil)
+			if _, ok := b.Min(); ok {
+				t.Errorf("unexpectedly found min in empty vector")
+			}
+			if _, ok := b.Max(); ok {
+				t.Errorf("unexpectedly found max in empty vector")
+			}
+			for _, arr := range [][]uint{{3}, {10, 64, 127}, {0, 191}} {
+				b := newBitSetWith(t, 8*3, endian, arr)
+				if result, ok := b.Min(); !ok || result != arr[0] {
+					t.Errorf("min == %v, %v, expected %v", result, ok, arr[0])
+				}
+				if result, ok := b.Max(); !ok || result != arr[len(arr)-1] {
+					t.Errorf("max == %v, %v, expected %v", result, ok, arr[len(arr)-1])
+				}
+			}
+		})
+	}
+}
+
+func TestBitSet_FindPrevOne(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			t.Run("success to find", func(t *testing.T) {
+				buf := make([]byte, 8*3)
+				b, err := New(buf, endian, false)
+				if err != nil {
+					t.Fatalf("failed to create bit vec %v", err)
+				}
+				arr := []uint{0, 1, 3, 6, 10, 64, 127}
+				for _, v := range arr {
+					if !b.Set(v) {
+						t.Errorf("failed to set %v", v)
+					}
+				}
+				v := uint(8*3*8 + 10)
+				for i := len(arr) - 1; i >= 0; i-- {
+					result, ok := b.FindPrevOne(v)
+					if !ok {
+						t.Fatalf("failed to find v : %v, result : %v", v, result)
+					}
+					if result != arr[i] {
+						t.Errorf("found value does not match v : %v, result : %v, expected : %v", v, result, arr[i])
+					}
+					if result == 0 {
+						break
+					}
+					v = result - 1
+				}
+				if result, ok := b.FindPrevOne(63); !ok || result != 10 {
+					t.Errorf("found value does not match v : 63, result : %v, expected : 10", result)
+				}
+			})
+
+			t.Run("all bit is 0", func(t *testing.T) {
+				buf := make([]byte, 8*3)
+				b, err := New(buf, endian, false)
+				if err != nil {
+					t.Fatalf("failed to create bit vec %v", err)
+				}
+				if result, ok := b.FindPrevOne(8*3*8 - 1); ok {
+					t.Errorf("unexpectedly found value result : %v", result)
+				}
+			})
+		})
+	}
+}
+
+func TestBitSet_Count(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			buf := make([]byte, 8*3)
+			b, err := New(buf, endian, false)
+			if err != nil {
+				t.Fatalf("failed to create bit vec %v", err)
+			}
+			arr := []uint{0, 1, 3, 6, 10, 64, 127}
+			for _, v := range arr {
+				if !b.Set(v) {
+					t.Errorf("failed to set %v", v)
+				}
+			}
+			if b.Count() != uint(len(arr)) {
+				t.Errorf("count == %v, expected %v", b.Count(), len(arr))
+			}
+		})
+	}
+}
+
+func TestBitSet_EnableCountCache(t *testing.T) {
+	for _, e1 := range endians {
+		for _, e2 := range endians {
+			t.Run(e1.String()+"_"+e2.String(), func(t *testing.T) {
+				// capacity after the vector is dirty to check extended words are counted
+				buf := make([]byte, 8*5)
+				for i := range buf[8*3:] {
+					buf[8*3+i] = 0xff
+				}
+				b, err := New(buf[:8*3], e1, true)
+				if err != nil {
+					t.Fatalf("failed to create bit vec %v", err)
+				}
+				for _, v := range []uint{0, 1, 3, 64, 127} {
+					b.Set(v)
+				}
+				other := newBitSetWith(t, 8*4, e2, []uint{1, 2, 11, 64, 128, 255})
+				b.EnableCountCache()
+				check := func(err error) {
+					if err != nil {
+						t.Errorf("failed to operate %v", err)
+					}
+				}
+				ops := []func(){
+					func() { b.Set(5) },
+					func() { b.Set(5) },
+					func() { b.Unset(1) },
+					func() { b.Unset(1) },
+					func() { b.SetTo(7, true) },
+					func() { b.SetTo(0, false) },
+					func() { b.Flip(3) },
+					func() { b.Flip(3) },
+					func() { b.Set(200) },
+					func() { b.SetRange(60, 130) },
+					func() { b.UnsetRange(62, 100) },
+					func() { b.FlipRange(10, 250) },
+					func() { b.SetWord(1, 0xff) },
+					func() { b.Complement() },
+					func() { b.ShiftLeft(3) },
+					func() { b.ShiftRight(70) },
+					func() { b.SetBitLen(200); b.Reverse() },
+					func() { b.RotateLeft(33) },
+					func() { check(b.Or(other)) },
+					func() { check(b.And(other)) },
+					func() { check(b.Xor(other)) },
+					func() { check(b.AndNot(other)) },
+					func() { _, err := b.CopyFrom(other); check(err) },
+					func() { check(Union(b, other, other.Clone())) },
+					func() { check(Intersection(b, other, other)) },
+					func() { check(other.AndNotInto(b, b.Clone())) },
+					func() { b.SetAll() },
+					func() { b.Drain(func(uint) {}) },
+					func() { b.SetFrom(3) },
+					func() { b.UnsetFrom(100) },
+					func() { b.Grow(8 * 5 * 8) },
+					func() { b.UnsetAll() },
+				}
+				for i, op := range ops {
+					op()
+					if b.Count() != countWords(b.vec) {
+						t.Errorf("op %v : cached count == %v, expected %v", i, b.Count(), countWords(b.vec))
+					}
+					if err := b.Validate(); err != nil {
+						t.Errorf("op %v : failed to validate %v", i, err)
+					}
+				}
+
+				b.SetAll()
+				data, _ := b.MarshalBinary()
+				decoded := newBitSetWith(t, 8, e2, nil)
+				decoded.EnableCountCache()
+				if err := decoded.UnmarshalBinary(data); err != nil || decoded.Count() != countWords(decoded.vec) {
+					t.Errorf("cached count of decoded vector == %v, err : %v", decoded.Count(), err)
+				}
+
+				b.Bytes()[0] = 0
+				b.EnableCountCache()
+				if b.Count() != countWords(b.vec) {
+					t.Errorf("recounted count == %v, expected %v", b.Count(), countWords(b.vec))
+				}
+				b.DisableCountCache()
+				b.Bytes()[1] = 0
+				if b.Count() != countWords(b.vec) {
+					t.Errorf("uncached count == %v, expected %v", b.Count(), countWords(b.vec))
+				}
+			})
+		}
+	}
+}
+
+func TestBitSet_CountWords(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			b := newBitSetWith(t, 8*3, endian, []uint{0, 1, 3, 6, 10, 64, 127, 191})
+			counts := b.CountWords()
+			expected := []uint{5, 2, 1}
+			if len(counts) != len(expected) {
+				t.Fatalf("counts %v, expected %v", counts, expected)
+			}
+			for i, v := range expected {
+				if counts[i] != v {
+					t.Errorf("counts %v, expected %v", counts, expected)
+				}
+			}
+		})
+	}
+}
+
+func TestBitSet_BlockCounts(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			b := newBitSetWith(t, 8*4, endian, []uint{0, 1, 3, 64, 127, 128, 149, 150, 200})
+			if !b.SetBitLen(150) {
+				t.Fatalf("failed to set bit len")
+			}
+			tests := []struct {
+				blockBits uint
+				expected  []uint
+			}{
+				{blockBits: 64, expected: []uint{3, 2, 2}},
+				{blockBits: 128, expected: []uint{5, 2}},
+				{blockBits: 256, expected: []uint{7}},
+			}
+			for _, test := range tests {
+				counts, err := b.BlockCounts(test.blockBits)
+				if err != nil {
+					t.Fatalf("failed to count blocks %v", err)
+				}
+				if len(counts) != len(test.expected) {
+					t.Fatalf("block %v : counts %v, expected %v", test.blockBits, counts, test.expected)
+				}
+				for i, v := range test.expected {
+					if counts[i] != v {
+						t.Errorf("block %v : counts %v, expected %v", test.blockBits, counts, test.expected)
+					}
+				}
+			}
+			for _, blockBits := range []uint{0, 8, 100} {
+				if _, err := b.BlockCounts(blockBits); err != ErrInvalidLength {
+					t.Errorf("block %v : err : %v, expected err : %v", blockBits, err, ErrInvalidLength)
+				}
+			}
+		})
+	}
+}
+
+func TestBitSet_Density(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			b := newBitSetWith(t, 8*2, endian, nil)
+			if d := b.Density(); d != 0 {
+				t.Errorf("density == %v, expected 0", d)
+			}
+			b.SetRange(0, 32)
+			if d := b.Density(); d != 0.25 {
+				t.Errorf("density == %v, expected 0.25", d)
+			}
+			b.SetRange(100, 128)
+			if !b.SetBitLen(64) {
+				t.Fatalf("failed to set bit len")
+			}
+			if d := b.Density(); d != 0.5 {
+				t.Errorf("density == %v, expected 0.5", d)
+			}
+			b.SetAll()
+			if d := b.Density(); d != 1 {
+				t.Errorf("density == %v, expected 1", d)
+			}
+			if !b.SetBitLen(0) || b.Density() != 0 {
+				t.Errorf("density of empty vector == %v, expected 0", b.Density())
+			}
+		})
+	}
+}
+
+func TestBitSet_FindLastZero(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			t.Run("success to find", func(t *testing.T) {
+				buf := make([]byte, 8*3)
+				b, err := New(buf, endian, false)
+				if err != nil {
+					t.Fatalf("failed to create bit vec %v", err)
+				}
+				for i := 0; i < 8*3*8; i++ {
+					if !b.Set(uint(i)) {
+						t.Errorf("failed to set %v", i)
+					}
+				}
+
+				tests := []struct {
+					result uint
+					unset  []uint
+				}{
+					{result: 0, unset: []uint{0}},
+					{result: 1, unset: []uint{0, 1}},
+					{result: 8, unset: []uint{0, 8}},
+					{result: 70, unset: []uint{3, 70}},
+					{result: 191, unset: []uint{191}},
+				}
+
+				for _, test := range tests {
+					for _, v := range test.unset {
+						if !b.Unset(v) {
+							t.Errorf("failed to unset %v", v)
+						}
+					}
+
+					result, ok := b.FindLastZero()
+					if !ok {
+						t.Errorf("not found last zero for %v", test.unset)
+					} else if result != test.result {
+						t.Errorf("last zero index %v, expected %v for %v", result, test.result, test.unset)
+					}
+
+					for _, v := range test.unset {
+						if !b.Set(v) {
+							t.Errorf("failed to set %v", v)
+						}
+					}
+				}
+			})
+
+			t.Run("all bit is 1", func(t *testing.T) {
+				buf := make([]byte, 8*3)
+				b, err := New(buf, endian, false)
+				if err != nil {
+					t.Fatalf("failed to create bit vec %v", err)
+				}
+				for i := 0; i < 8*3*8; i++ {
+					if !b.Set(uint(i)) {
+						t.Errorf("failed to set %v", i)
+					}
+				}
+				if result, ok := b.FindLastZero(); ok {
+					t.Errorf("unexpectedly found value result : %v", result)
+				}
+			})
+		})
+	}
+}
+
+func TestBitSet_CountRange(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			buf := make([]byte, 8*3)
+			b, err := New(buf, endian, false)
+			if err != nil {
+				t.Fatalf("failed to create bit vec %v", err)
+			}
+			arr := []uint{0, 1, 3, 6, 10, 63, 64, 127, 130, 191}
+			for _, v := range arr {
+				if !b.Set(v) {
+					t.Errorf("failed to set %v", v)
+				}
+			}
+			tests := []struct {
+				start, end uint
+				expected   uint
+			}{
+				{start: 0, end: 0, expected: 0},
+				{start: 0, end: 1, expected: 1},
+				{start: 1, end: 10, expected: 3},
+				{start: 1, end: 11, expected: 4},
+				{start: 10, end: 2, expected: 0},
+				{start: 60, end: 70, expected: 2},
+				{start: 63, end: 128, expected: 3},
+				{start: 0, end: 8 * 3 * 8, expected: uint(len(arr))},
+				{start: 128, end: 1000, expected: 2},
+				{start: 1000, end: 2000, expected: 0},
+			}
+			for _, test := range tests {
+				if result := b.CountRange(test.start, test.end); result != test.expected {
+					t.Errorf("count [%v, %v) == %v, expected %v", test.start, test.end, result, test.expected)
+				}
+			}
+		})
+	}
+}
+
+func TestBitSet_SetRange(t *testing.T) {
+	tests := []struct {
+		start, end uint
+	}{
+		{start: 0, end: 0},
+		{start: 0, end: 1},
+		{start: 3, end: 10},
+		{start: 0, end: 64},
+		{start: 60, end: 70},
+		{start: 1, end: 191},
+		{start: 0, end: 8 * 3 * 8},
+	}
+	for _, test := range tests {
+		var vecs []*BitSet
+		for _, endian := range endians {
+			buf := make([]byte, 8*3)
+			b, err := New(buf, endian, false)
+			if err != nil {
+				t.Fatalf("failed to create bit vec %v", err)
+			}
+			if !b.SetRange(test.start, test.end) {
+				t.Errorf("%v : failed to set range [%v, %v)", endian, test.start, test.end)
+			}
+			for i := uint(0); i < 8*3*8; i++ {
+				if expected := test.start <= i && i < test.end; b.Get(i) != expected {
+					t.Errorf("%v : get(%v) == %v after set range [%v, %v)", endian, i, !expected, test.start, test.end)
+				}
+			}
+			vecs = append(vecs, b)
+		}
+		for i := uint(0); i < 8*3*8; i++ {
+			if vecs[0].Get(i) != vecs[1].Get(i) {
+				t.Errorf("bit %v differs between endians after set range [%v, %v)", i, test.start, test.end)
+			}
+		}
+	}
+
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			t.Run("out of range", func(t *testing.T) {
+				buf := make([]byte, 16)
+				b, err := New(buf, endian, false)
+				if err != nil {
+					t.Fatalf("failed to create bit vec %v", err)
+				}
+				if b.SetRange(10, 16*8+1) {
+					t.Errorf("invalid to success to set range over the last bit")
+				}
+				if b.Count() != 0 {
+					t.Errorf("vector is modified by failed set range")
+				}
+			})
+
+			t.Run("extend vector", func(t *testing.T) {
+				buf := make([]byte, 16)
+				b, err := New(buf, endian, true)
+				if err != nil {
+					t.Fatalf("failed to create bit vec %v", err)
+				}
+				if !b.SetRange(120, 200) {
+					t.Errorf("failed to set range over the last bit")
+				}
+				if b.Count() != 80 || !b.Get(199) || b.Get(200) {
+					t.Errorf("failed to set range in extended vector")
+				}
+			})
+		})
+	}
+}
+
+func TestBitSet_UnsetRange(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			t.Run("success to unset", func(t *testing.T) {
+				tests := []struct {
+					start, end uint
+				}{
+					{start: 0, end: 0},
+					{start: 0, end: 1},
+					{start: 3, end: 10},
+					{start: 0, end: 64},
+					{start: 60, end: 70},
+					{start: 1, end: 191},
+					{start: 0, end: 8 * 3 * 8},
+				}
+				for _, test := range tests {
+					buf := make([]byte, 8*3)
+					b, err := New(buf, endian, false)
+					if err != nil {
+						t.Fatalf("failed to create bit vec %v", err)
+					}
+					for i := 0; i < 8*3*8; i++ {
+						if !b.Set(uint(i)) {
+							t.Errorf("failed to set %v", i)
+						}
+					}
+					if !b.UnsetRange(test.start, test.end) {
+						t.Errorf("failed to unset range [%v, %v)", test.start, test.end)
+					}
+					for i := uint(0); i < 8*3*8; i++ {
+						if expected := i < test.start || test.end <= i; b.Get(i) != expected {
+							t.Errorf("get(%v) == %v after unset range [%v, %v)", i, !expected, test.start, test.end)
+						}
+					}
+				}
+			})
+
+			t.Run("out of range", func(t *testing.T) {
+				buf := make([]byte, 16)
+				b, err := New(buf, endian, true)
+				if err != nil {
+					t.Fatalf("failed to create bit vec %v", err)
+				}
+				for i := 0; i < 16*8; i++ {
+					if !b.Set(uint(i)) {
+						t.Errorf("failed to set %v", i)
+					}
+				}
+				if b.UnsetRange(10, 16*8+1) {
+					t.Errorf("invalid to success to unset range over the last bit")
+				}
+				if b.Count() != 16*8 {
+					t.Errorf("vector is modified by failed unset range")
+				}
+			})
+		})
+	}
+}
+
+func TestBitSet_SetFrom_UnsetFrom(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			for _, start := range []uint{0, 3, 64, 70, 149, 150, 1000} {
+				b := newBitSetWith(t, 8*3, endian, []uint{191})
+				if !b.SetBitLen(150) {
+					t.Fatalf("failed to set bit len")
+				}
+				b.SetFrom(start)
+				for i := uint(0); i < 8*3*8; i++ {
+					if expected := i >= start && i < 150; b.Get(i) != expected {
+						t.Errorf("set from %v : get(%v) == %v, expected %v", start, i, b.Get(i), expected)
+					}
+				}
+				b.SetAll()
+				b.UnsetFrom(start)
+				for i := uint(0); i < 8*3*8; i++ {
+					if expected := i < start && i < 150; b.Get(i) != expected {
+						t.Errorf("unset from %v : get(%v) == %v, expected %v", start, i, b.Get(i), expected)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestBitSet_Allocate_Free(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			b := newBitSetWith(t, 8*3, endian, []uint{4})
+			if !b.SetBitLen(150) {
+				t.Fatalf("failed to set bit len")
+			}
+			tests := []struct {
+				n        uint
+				expected uint
+				ok       bool
+			}{
+				{n: 3, expected: 0, ok: true},
+				{n: 3, expected: 5, ok: true},
+				{n: 1, expected: 3, ok: true},
+				{n: 100, expected: 8, ok: true},
+				{n: 43, expected: 0, ok: false},
+				{n: 42, expected: 108, ok: true},
+				{n: 1, expected: 0, ok: false},
+			}
+			for _, test := range tests {
+				orig := b.Clone()
+				result, ok := b.Allocate(test.n)
+				if ok != test.ok || ok && result != test.expected {
+					t.Errorf("allocate %v == %v, %v, expected %v, %v", test.n, result, ok, test.expected, test.ok)
+				}
+				if !ok && !b.Equal(orig) {
+					t.Errorf("allocate %v modified vector on failure", test.n)
+				}
+			}
+			if !b.All() || b.AnySetInRange(150, 192) {
+				t.Errorf("allocated bits %v", b)
+			}
+			if !b.Free(8, 100) || b.AnySetInRange(8, 108) || !b.Get(7) || !b.Get(108) {
+				t.Errorf("failed to free %v", b)
+			}
+			if result, ok := b.Allocate(50); !ok || result != 8 {
+				t.Errorf("allocate 50 == %v, %v, expected 8, true", result, ok)
+			}
+			orig := b.Clone()
+			if b.Free(140, 11) || !b.Equal(orig) {
+				t.Errorf("freed out of bit len")
+			}
+		})
+	}
+}
+
+func TestBitSet_AllSetInRange_AnySetInRange(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			b := newBitSetWith(t, 8*3, endian, []uint{160, 191})
+			b.SetRange(3, 130)
+			if !b.SetBitLen(170) {
+				t.Fatalf("failed to set bit len")
+			}
+			tests := []struct {
+				start, end uint
+				all, any   bool
+			}{
+				{start: 3, end: 130, all: true, any: true},
+				{start: 4, end: 10, all: true, any: true},
+				{start: 64, end: 128, all: true, any: true},
+				{start: 2, end: 130, all: false, any: true},
+				{start: 3, end: 131, all: false, any: true},
+				{start: 0, end: 3, all: false, any: false},
+				{start: 130, end: 160, all: false, any: false},
+				{start: 130, end: 161, all: false, any: true},
+				{start: 160, end: 161, all: true, any: true},
+				{start: 170, end: 192, all: true, any: false},
+				{start: 171, end: 1000, all: true, any: false},
+				{start: 10, end: 10, all: true, any: false},
+			}
+			for _, test := range tests {
+				if result := b.AllSetInRange(test.start, test.end); result != test.all {
+					t.Errorf("all set in [%v, %v) == %v, expected %v", test.start, test.end, result, test.all)
+				}
+				if result := b.AnySetInRange(test.start, test.end); result != test.any {
+					t.Errorf("any set in [%v, %v) == %v, expected %v", test.start, test.end, result, test.any)
+				}
+			}
+		})
+	}
+}
+
+func TestBitSet_FlipRange(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			t.Run("flip twice", func(t *testing.T) {
+				buf := make([]byte, 8*3)
+				b, err := New(buf, endian, false)
+				if err != nil {
+					t.Fatalf("failed to create bit vec %v", err)
+				}
+				arr := []uint{0, 1, 3, 6, 10, 64, 127}
+				for _, v := range arr {
+					if !b.Set(v) {
+						t.Errorf("failed to set %v", v)
+					}
+				}
+				orig := append([]byte{}, buf...)
+				for _, r := range [][2]uint{{0, 1}, {2, 9}, {5, 130}, {0, 8 * 3 * 8}} {
+					if !b.FlipRange(r[0], r[1]) || !b.FlipRange(r[0], r[1]) {
+						t.Errorf("failed to flip range %v", r)
+					}
+					for i, v := range orig {
+						if buf[i] != v {
+							t.Errorf("flip range %v twice changes i : %v, v : %v, expected %v", r, i, buf[i], v)
+						}
+					}
+				}
+			})
+
+			t.Run("across word boundary", func(t *testing.T) {
+				buf := make([]byte, 8*3)
+				b, err := New(buf, endian, false)
+				if err != nil {
+					t.Fatalf("failed to create bit vec %v", err)
+				}
+				for _, v := range []uint{60, 64, 66} {
+					if !b.Set(v) {
+						t.Errorf("failed to set %v", v)
+					}
+				}
+				if !b.FlipRange(58, 68) {
+					t.Errorf("failed to flip range")
+				}
+				for i := uint(0); i < 8*3*8; i++ {
+					expected := 58 <= i && i < 68 && i != 60 && i != 64 && i != 66
+					if b.Get(i) != expected {
+						t.Errorf("get(%v) == %v, expected %v", i, !expected, expected)
+					}
+				}
+			})
+
+			t.Run("out of range", func(t *testing.T) {
+				buf := make([]byte, 16)
+				b, err := New(buf, endian, false)
+				if err != nil {
+					t.Fatalf("failed to create bit vec %v", err)
+				}
+				if b.FlipRange(10, 16*8+1) {
+					t.Errorf("invalid to success to flip range over the last bit")
+				}
+				if b.Count() != 0 {
+					t.Errorf("vector is modified by failed flip range")
+				}
+			})
+		})
+	}
+}
+
+func TestBitSet_SetAll_UnsetAll(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			buf := make([]byte, 8*3)
+			b, err := New(buf, endian, false)
+			if err != nil {
+				t.Fatalf("failed to create bit vec %v", err)
+			}
+			b.SetAll()
+			for i := 0; i < 8*3*8; i++ {
+				if !b.Get(uint(i)) {
+					t.Errorf("bit %v is not set after set all", i)
+				}
+			}
+			b.UnsetAll()
+			for i, v := range buf {
+				if v != 0 {
+					t.Errorf("unset all not match i : %v, v : %v, expected 0", i, v)
+				}
+			}
+		})
+	}
+}
+
+func TestParseBits_Bits(t *testing.T) {
+	if _, err := ParseBits("0101", nil); err != ErrInvalidEndianness {
+		t.Errorf("err : %v, expected err : %v", err, ErrInvalidEndianness)
+	}
+	for _, s := range []string{"0102", "01-0", "1\n"} {
+		if _, err := ParseBits(s, binary.BigEndian); err != ErrInvalidFormat {
+			t.Errorf("parse %q err : %v, expected err : %v", s, err, ErrInvalidFormat)
+		}
+	}
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			s := "1101_0010 1" + strings.Repeat("0", 53) + "1" + strings.Repeat("0", 7) + "01"
+			b, err := ParseBits(s, endian)
+			if err != nil {
+				t.Fatalf("failed to parse %v", err)
+			}
+			if b.BitLen() != 72 || b.WordCount() != 2 {
+				t.Errorf("bit len == %v, word count == %v", b.BitLen(), b.WordCount())
+			}
+			assertBits(t, b, []uint{0, 1, 3, 6, 8, 62, 71})
+			expected := strings.NewReplacer(" ", "", "_", "").Replace(s)
+			if result := b.Bits(); result != expected {
+				t.Errorf("bits == %v, expected %v", result, expected)
+			}
+			b.Set(100)
+			if result := b.Bits(); result != expected {
+				t.Errorf("bits with padding == %v, expected %v", result, expected)
+			}
+			if b, err := ParseBits("", endian); err != nil || b.BitLen() != 0 || b.Bits() != "" {
+				t.Errorf("failed to parse empty string err : %v", err)
+			}
+		})
+	}
+}
+
+func TestBitSet_String(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			buf := make([]byte, 8*3)
+			b, err := New(buf, endian, false)
+			if err != nil {
+				t.Fatalf("failed to create bit vec %v", err)
+			}
+			if s := b.String(); s != "{}" {
+				t.Errorf("string == %v, expected {}", s)
+			}
+			for _, v := range []uint{0, 3, 64, 130} {
+				if !b.Set(v) {
+					t.Errorf("failed to set %v", v)
+				}
+			}
+			if s := b.String(); s != "{0,3,64,130}" {
+				t.Errorf("string == %v, expected {0,3,64,130}", s)
+			}
+
+			large, err := New(make([]byte, maxStringBits), endian, false)
+			if err != nil {
+				t.Fatalf("failed to create bit vec %v", err)
+			}
+			large.SetAll()
+			s := large.String()
+			if !strings.HasPrefix(s, "{0,1,2,") || !strings.HasSuffix(s, ",1022,1023,...}") {
+				t.Errorf("string of large vector is not omitted %v", s)
+			}
+		})
+	}
+}
+
+func TestBitSet_Any_None_All(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			for _, length := range []uint{8 * 3 * 8, 100} {
+				buf := make([]byte, 8*3)
+				b, err := New(buf, endian, false)
+				if err != nil {
+					t.Fatalf("failed to create bit vec %v", err)
+				}
+				if !b.SetBitLen(length) {
+					t.Fatalf("failed to set bit len %v", length)
+				}
+				if b.Any() || !b.None() || b.All() {
+					t.Errorf("bit len %v : empty vector any : %v, none : %v, all : %v", length, b.Any(), b.None(), b.All())
+				}
+				if !b.Set(length - 1) {
+					t.Errorf("failed to set %v", length-1)
+				}
+				if !b.Any() || b.None() || b.All() {
+					t.Errorf("bit len %v : vector any : %v, none : %v, all : %v", length, b.Any(), b.None(), b.All())
+				}
+				if !b.SetRange(0, length) {
+					t.Errorf("failed to set range")
+				}
+				if !b.Any() || b.None() || !b.All() {
+					t.Errorf("bit len %v : full vector any : %v, none : %v, all : %v", length, b.Any(), b.None(), b.All())
+				}
+				if length < 8*3*8 {
+					b.UnsetAll()
+					if b.Set(length) {
+						t.Errorf("padding %v is set", length)
+					}
+					if b.Any() {
+						t.Errorf("bit len %v : padding bit is checked by any", length)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestBitSet_Complement(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			buf := make([]byte, 8*3)
+			b, err := New(buf, endian, false)
+			if err != nil {
+				t.Fatalf("failed to create bit vec %v", err)
+			}
+			arr := []uint{0, 1, 3, 6, 10, 64, 127}
+			for _, v := range arr {
+				if !b.Set(v) {
+					t.Errorf("failed to set %v", v)
+				}
+			}
+			b.Complement()
+			for _, v := range arr {
+				if b.Get(v) {
+					t.Errorf("bit %v is set after complement", v)
+				}
+			}
+			for _, v := range []uint{2, 4, 5, 7, 8, 100, 128, 8*3*8 - 1} {
+				if !b.Get(v) {
+					t.Errorf("bit %v is not set after complement", v)
+				}
+			}
+			if b.Count() != 8*3*8-uint(len(arr)) {
+				t.Errorf("count == %v, expected %v", b.Count(), 8*3*8-len(arr))
+			}
+		})
+	}
+}
+
+func TestBitSet_ShiftLeft(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			arr := []uint{0, 1, 3, 6, 10, 63, 64, 127, 150}
+			for _, n := range []uint{0, 1, 64, 70, 8*3*8 - 1, 8 * 3 * 8, 1000} {
+				buf := make([]byte, 8*3)
+				b, err := New(buf, endian, false)
+				if err != nil {
+					t.Fatalf("failed to create bit vec %v", err)
+				}
+				for _, v := range arr {
+					if !b.Set(v) {
+						t.Errorf("failed to set %v", v)
+					}
+				}
+				b.ShiftLeft(n)
+				expected := make(map[uint]bool)
+				for _, v := range arr {
+					expected[v+n] = true
+				}
+				for i := uint(0); i < 8*3*8; i++ {
+					if b.Get(i) != expected[i] {
+						t.Errorf("shift %v : get(%v) == %v, expected %v", n, i, b.Get(i), expected[i])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestBitSet_ShiftRight(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			arr := []uint{0, 1, 3, 6, 10, 63, 64, 127, 150, 191}
+			for _, n := range []uint{0, 1, 64, 70, 8*3*8 - 1, 8 * 3 * 8, 1000} {
+				buf := make([]byte, 8*3)
+				b, err := New(buf, endian, false)
+				if err != nil {
+					t.Fatalf("failed to create bit vec %v", err)
+				}
+				for _, v := range arr {
+					if !b.Set(v) {
+						t.Errorf("failed to set %v", v)
+					}
+				}
+				b.ShiftRight(n)
+				expected := make(map[uint]bool)
+				for _, v := range arr {
+					if v >= n {
+						expected[v-n] = true
+					}
+				}
+				for i := uint(0); i < 8*3*8; i++ {
+					if b.Get(i) != expected[i] {
+						t.Errorf("shift %v : get(%v) == %v, expected %v", n, i, b.Get(i), expected[i])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestBitSet_ShiftLeft_ShiftRight(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			arr := []uint{0, 1, 3, 6, 10, 63, 64, 127, 150, 191}
+			for _, n := range []uint{1, 7, 64, 70, 129} {
+				buf := make([]byte, 8*3)
+				b, err := New(buf, endian, false)
+				if err != nil {
+					t.Fatalf("failed to create bit vec %v", err)
+				}
+				for _, v := range arr {
+					if !b.Set(v) {
+						t.Errorf("failed to set %v", v)
+					}
+				}
+				b.ShiftLeft(n)
+				b.ShiftRight(n)
+				for _, v := range arr {
+					if expected := v+n < 8*3*8; b.Get(v) != expected {
+						t.Errorf("shift %v : get(%v) == %v, expected %v", n, v, b.Get(v), expected)
+					}
+				}
+				if b.Count() != b.CountRange(0, 8*3*8-n) {
+					t.Errorf("shift %v : bits remain in high edge", n)
+				}
+			}
+		})
+	}
+}
+
+func TestBitSet_Reverse(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			arr := []uint{0, 1, 3, 6, 10, 63, 64, 127, 149}
+			for _, length := range []uint{150, 8 * 3 * 8, 64} {
+				b := newBitSetWith(t, 8*3, endian, nil)
+				for _, v := range arr {
+					if v < length && !b.Set(v) {
+						t.Errorf("failed to set %v", v)
+					}
+				}
+				if !b.SetBitLen(length) {
+					t.Fatalf("failed to set bit len %v", length)
+				}
+				orig := b.Clone()
+				b.Reverse()
+				for i := uint(0); i < 8*3*8; i++ {
+					expected := i < length && orig.Get(length-1-i)
+					if b.Get(i) != expected {
+						t.Errorf("length %v : get(%v) == %v, expected %v", length, i, b.Get(i), expected)
+					}
+				}
+				b.Reverse()
+				if !b.Equal(orig) {
+					t.Errorf("length %v : reversed twice %v, expected %v", length, b, orig)
+				}
+			}
+		})
+	}
+}
+
+func TestBitSet_RotateLeft(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			arr := []uint{0, 1, 3, 6, 10, 63, 64, 127, 149}
+			for _, length := range []uint{150, 8 * 3 * 8} {
+				for _, n := range []uint{0, 1, 7, 64, 70, 149, length, length + 3} {
+					b := newBitSetWith(t, 8*3, endian, arr)
+					if !b.SetBitLen(length) {
+						t.Fatalf("failed to set bit len %v", length)
+					}
+					b.RotateLeft(n)
+					expected := make(map[uint]bool)
+					for _, v := range arr {
+						expected[(v+n)%length] = true
+					}
+					for i := uint(0); i < 8*3*8; i++ {
+						if b.Get(i) != expected[i] {
+							t.Errorf("length %v, rotate %v : get(%v) == %v, expected %v", length, n, i, b.Get(i), expected[i])
+						}
+					}
+				}
+			}
+			b := newBitSetWith(t, 8*3, endian, arr)
+			if !b.SetBitLen(150) {
+				t.Fatalf("failed to set bit len")
+			}
+			orig := b.Clone()
+			b.RotateLeft(150)
+			if !b.Equal(orig) {
+				t.Errorf("rotated by bit len %v, expected %v", b, orig)
+			}
+		})
+	}
+}
+
+func TestBitSet_Validate(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			buf := make([]byte, 8*3)
+			b, err := New(buf, endian, true)
+			if err != nil {
+				t.Fatalf("failed to create bit vec %v", err)
+			}
+			if err := b.Validate(); err != nil {
+				t.Errorf("failed to validate %v", err)
+			}
+			if !b.Set(1000) {
+				t.Errorf("failed to extend")
+			}
+			if err := b.Validate(); err != nil {
+				t.Errorf("failed to validate extended vector %v", err)
+			}
+			b.length = uint(len(b.vec))*wordBits + 1
+			if err := b.Validate(); err != ErrInconsistent {
+				t.Errorf("err : %v, expected err : %v", err, ErrInconsistent)
+			}
+		})
+	}
+}
+
+func TestBitSet_LeadingZeros_TrailingZeros(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			tests := []struct {
+				set             []uint
+				leading, trails uint
+			}{
+				{set: nil, leading: 150, trails: 150},
+				{set: []uint{0}, leading: 0, trails: 149},
+				{set: []uint{149}, leading: 149, trails: 0},
+				{set: []uint{3, 64, 100}, leading: 3, trails: 49},
+				{set: []uint{70, 160}, leading: 70, trails: 79},
+				{set: []uint{160}, leading: 150, trails: 150},
+			}
+			for _, test := range tests {
+				b := newBitSetWith(t, 8*3, endian, test.set)
+				if !b.SetBitLen(150) {
+					t.Fatalf("failed to set bit len")
+				}
+				if result := b.LeadingZeros(); result != test.leading {
+					t.Errorf("leading zeros of %v == %v, expected %v", test.set, result, test.leading)
+				}
+				if result := b.TrailingZeros(); result != test.trails {
+					t.Errorf("trailing zeros of %v == %v, expected %v", test.set, result, test.trails)
+				}
 			}
 		})
 	}
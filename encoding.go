@@ -0,0 +1,244 @@
+package bitset
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"io"
+)
+
+// binary format of BitSet is
+//
+//	magic      : 1 byte  (binaryMagic)
+//	byte order : 1 byte  (0 : Little Endian, 1 : Big Endian)
+//	words      : 8 bytes (number of words in Big Endian)
+//	length     : 8 bytes (BitLen() in Big Endian)
+//	vector     : words * 8 bytes (stored words in the byte order)
+//
+// vector is written as stored without any conversion and the byte order
+// is recorded in header, so it is decoded correctly on any host.
+const (
+	binaryMagic      byte = 0xb5
+	binaryHeaderSize      = 18
+)
+
+const (
+	binaryLittleEndian byte = iota
+	binaryBigEndian
+)
+
+// DefaultMaxReadWords is the max number of words ReadFrom and ReadBitSet allocate.
+// header declaring more words is rejected with ErrTooLarge before allocation.
+const DefaultMaxReadWords = 1 << 24
+
+func (b *BitSet) putHeader(buf []byte) {
+	buf[0] = binaryMagic
+	if b.order() == binary.BigEndian {
+		buf[1] = binaryBigEndian
+	} else {
+		buf[1] = binaryLittleEndian
+	}
+	binary.BigEndian.PutUint64(buf[2:], uint64(len(b.vec)))
+	binary.BigEndian.PutUint64(buf[10:], uint64(b.length))
+}
+
+func parseHeader(buf []byte) (order binary.ByteOrder, words, length uint64, err error) {
+	if buf[0] != binaryMagic {
+		return nil, 0, 0, ErrInvalidFormat
+	}
+	switch buf[1] {
+	case binaryLittleEndian:
+		order = binary.LittleEndian
+	case binaryBigEndian:
+		order = binary.BigEndian
+	default:
+		return nil, 0, 0, ErrInvalidEndianness
+	}
+	if err = checkOrder(order); err != nil {
+		return nil, 0, 0, err
+	}
+	words = binary.BigEndian.Uint64(buf[2:])
+	length = binary.BigEndian.Uint64(buf[10:])
+	if words > uint64(^uint(0)>>log2WordSize) || length > words*wordBits {
+		return nil, 0, 0, ErrInvalidFormat
+	}
+	return order, words, length, nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (b *BitSet) MarshalBinary() ([]byte, error) {
+	return b.AppendTo(make([]byte, 0, binaryHeaderSize+len(b.vec)*wordBytes)), nil
+}
+
+// AppendTo appends b in the format of MarshalBinary to dst and returns the extended slice.
+// it does not allocate if dst has enough capacity.
+func (b *BitSet) AppendTo(dst []byte) []byte {
+	n, size := len(dst), binaryHeaderSize+len(b.vec)*wordBytes
+	if cap(dst)-n < size {
+		grown := make([]byte, n, n+size)
+		copy(grown, dst)
+		dst = grown
+	}
+	dst = dst[:n+size]
+	b.putHeader(dst[n:])
+	copy(dst[n+binaryHeaderSize:], b.Bytes())
+	return dst
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+// decoded vector does not share memory with data.
+func (b *BitSet) UnmarshalBinary(data []byte) error {
+	if len(data) < binaryHeaderSize {
+		return ErrInvalidFormat
+	}
+	order, words, length, err := parseHeader(data)
+	if err != nil {
+		return err
+	}
+	body := data[binaryHeaderSize:]
+	if len(body)%wordBytes != 0 || uint64(len(body)/wordBytes) != words {
+		return ErrInvalidFormat
+	}
+	nb := BitSet{
+		vec:    make([]uint64, words),
+		swap:   order != hostEndian,
+		extend: b.extend,
+		cached: b.cached,
+		length: uint(length),
+	}
+	copy(nb.Bytes(), body)
+	nb.clearPadding()
+	nb.recount()
+	*b = nb
+	return nil
+}
+
+// WriteTo implements io.WriterTo.
+// it writes the same format as MarshalBinary without copying the vector.
+func (b *BitSet) WriteTo(w io.Writer) (int64, error) {
+	var header [binaryHeaderSize]byte
+	b.putHeader(header[:])
+	n, err := w.Write(header[:])
+	if err != nil {
+		return int64(n), err
+	}
+	m, err := w.Write(b.Bytes())
+	return int64(n + m), err
+}
+
+// ReadFrom implements io.ReaderFrom.
+// it reads the format written by WriteTo or MarshalBinary.
+// short read is reported as io.ErrUnexpectedEOF and
+// more words than DefaultMaxReadWords is reported as ErrTooLarge.
+func (b *BitSet) ReadFrom(r io.Reader) (int64, error) {
+	return b.readFrom(r, DefaultMaxReadWords)
+}
+
+func (b *BitSet) readFrom(r io.Reader, maxWords uint64) (int64, error) {
+	var header [binaryHeaderSize]byte
+	n, err := io.ReadFull(r, header[:])
+	if err != nil {
+		return int64(n), unexpectedEOF(err)
+	}
+	order, words, length, err := parseHeader(header[:])
+	if err != nil {
+		return int64(n), err
+	}
+	if words > maxWords {
+		return int64(n), ErrTooLarge
+	}
+	nb := BitSet{
+		vec:    make([]uint64, words),
+		swap:   order != hostEndian,
+		extend: b.extend,
+		cached: b.cached,
+		length: uint(length),
+	}
+	m, err := io.ReadFull(r, nb.Bytes())
+	if err != nil {
+		return int64(n + m), unexpectedEOF(err)
+	}
+	nb.clearPadding()
+	nb.recount()
+	*b = nb
+	return int64(n + m), nil
+}
+
+// ReadBitSet reads *BitSet in the format written by WriteTo or MarshalBinary.
+// magic, byte order and size in header are verified before the vector is allocated.
+// more words than DefaultMaxReadWords is reported as ErrTooLarge.
+func ReadBitSet(r io.Reader) (*BitSet, error) {
+	return ReadBitSetMax(r, DefaultMaxReadWords)
+}
+
+// ReadBitSetMax reads *BitSet as ReadBitSet.
+// more words than maxWords is reported as ErrTooLarge.
+func ReadBitSetMax(r io.Reader, maxWords uint64) (*BitSet, error) {
+	b := &BitSet{}
+	if _, err := b.readFrom(r, maxWords); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+type jsonBitSet struct {
+	Length uint   `json:"length"`
+	Order  string `json:"order"`
+	Vector []byte `json:"vector"`
+}
+
+// MarshalJSON implements json.Marshaler.
+// vector is encoded as base64 of stored bytes with its byte order.
+func (b *BitSet) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonBitSet{
+		Length: b.length,
+		Order:  b.order().String(),
+		Vector: b.Bytes(),
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (b *BitSet) UnmarshalJSON(data []byte) error {
+	var v jsonBitSet
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	order, err := ParseEndian(v.Order)
+	if err != nil {
+		return err
+	}
+	if err := checkOrder(order); err != nil {
+		return err
+	}
+	if len(v.Vector)%wordBytes != 0 || v.Length > uint(len(v.Vector))*8 {
+		return ErrInvalidFormat
+	}
+	nb := BitSet{
+		vec:    make([]uint64, len(v.Vector)/wordBytes),
+		swap:   order != hostEndian,
+		extend: b.extend,
+		cached: b.cached,
+		length: v.Length,
+	}
+	copy(nb.Bytes(), v.Vector)
+	nb.clearPadding()
+	nb.recount()
+	*b = nb
+	return nil
+}
+
+// GobEncode implements gob.GobEncoder.
+func (b *BitSet) GobEncode() ([]byte, error) {
+	return b.MarshalBinary()
+}
+
+// GobDecode implements gob.GobDecoder.
+func (b *BitSet) GobDecode(data []byte) error {
+	return b.UnmarshalBinary(data)
+}
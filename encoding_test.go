@@ -0,0 +1,281 @@
+package bitset
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"io"
+	"testing"
+)
+
+func TestBitSet_MarshalBinary(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			arr := []uint{0, 1, 3, 6, 10, 64, 127, 191}
+			b := newBitSetWith(t, 8*3, endian, arr)
+			if !b.SetBitLen(150) {
+				t.Fatalf("failed to set bit len")
+			}
+			data, err := b.MarshalBinary()
+			if err != nil {
+				t.Fatalf("failed to marshal %v", err)
+			}
+			if len(data) != binaryHeaderSize+8*3 {
+				t.Fatalf("len(data) == %v, expected %v", len(data), binaryHeaderSize+8*3)
+			}
+			for i, v := range b.Bytes() {
+				if data[binaryHeaderSize+i] != v {
+					t.Errorf("vector is not written as stored i : %v, v : %v, expected %v", i, data[binaryHeaderSize+i], v)
+				}
+			}
+
+			var decoded BitSet
+			if err := decoded.UnmarshalBinary(data); err != nil {
+				t.Fatalf("failed to unmarshal %v", err)
+			}
+			if decoded.order() != endian {
+				t.Errorf("decoded byte order %v, expected %v", decoded.order(), endian)
+			}
+			if decoded.BitLen() != 150 {
+				t.Errorf("decoded bit len %v, expected %v", decoded.BitLen(), 150)
+			}
+			assertBits(t, &decoded, arr[:len(arr)-1])
+			if &decoded.Bytes()[0] == &data[binaryHeaderSize] {
+				t.Errorf("decoded vector shares memory with data")
+			}
+		})
+	}
+}
+
+func TestBitSet_UnmarshalBinary(t *testing.T) {
+	var b BitSet
+	if err := b.UnmarshalBinary([]byte{binaryMagic, 1, 0}); err != ErrInvalidFormat {
+		t.Errorf("err : %v, expected err : %v", err, ErrInvalidFormat)
+	}
+
+	data := make([]byte, binaryHeaderSize+8)
+	data[0] = binaryMagic
+	data[1] = binaryBigEndian
+	binary.BigEndian.PutUint64(data[2:], 1)
+	binary.BigEndian.PutUint64(data[10:], 64)
+	data[binaryHeaderSize+7] = 0x03
+	if err := b.UnmarshalBinary(data); err != nil {
+		t.Fatalf("failed to unmarshal %v", err)
+	}
+	assertBits(t, &b, []uint{0, 1})
+
+	tests := map[string]struct {
+		modify func(data []byte) []byte
+		err    error
+	}{
+		"invalid magic":       {modify: func(data []byte) []byte { data[0] = 0; return data }, err: ErrInvalidFormat},
+		"invalid byte order":  {modify: func(data []byte) []byte { data[1] = 2; return data }, err: ErrInvalidEndianness},
+		"too short vector":    {modify: func(data []byte) []byte { return data[:len(data)-1] }, err: ErrInvalidFormat},
+		"too many words":      {modify: func(data []byte) []byte { binary.BigEndian.PutUint64(data[2:], 2); return data }, err: ErrInvalidFormat},
+		"too long bit length": {modify: func(data []byte) []byte { binary.BigEndian.PutUint64(data[10:], 65); return data }, err: ErrInvalidFormat},
+	}
+	for name, test := range tests {
+		if err := b.UnmarshalBinary(test.modify(append([]byte{}, data...))); err != test.err {
+			t.Errorf("%v err : %v, expected err : %v", name, err, test.err)
+		}
+	}
+}
+
+func TestBitSet_AppendTo(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			b := newBitSetWith(t, 8*3, endian, []uint{0, 1, 3, 6, 10, 64, 127, 191})
+			data, _ := b.MarshalBinary()
+			prefix := []byte{1, 2, 3}
+			result := b.AppendTo(prefix)
+			if !bytes.Equal(result[:3], prefix) || !bytes.Equal(result[3:], data) {
+				t.Errorf("appended bytes %v, expected %v", result, append(prefix, data...))
+			}
+
+			buf := make([]byte, 0, len(data)*2)
+			if allocs := testing.AllocsPerRun(10, func() { buf = b.AppendTo(buf[:0]) }); allocs != 0 {
+				t.Errorf("append to allocates %v times", allocs)
+			}
+			if !bytes.Equal(buf, data) {
+				t.Errorf("appended bytes %v, expected %v", buf, data)
+			}
+			buf = b.AppendTo(buf)
+			var decoded BitSet
+			if n, err := decoded.ReadFrom(bytes.NewReader(buf[len(data):])); err != nil || n != int64(len(data)) {
+				t.Fatalf("failed to read appended vector n : %v, err : %v", n, err)
+			}
+			assertBits(t, &decoded, []uint{0, 1, 3, 6, 10, 64, 127, 191})
+		})
+	}
+}
+
+func TestBitSet_WriteTo_ReadFrom(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			arr := []uint{0, 1, 3, 6, 10, 64, 127, 191}
+			b := newBitSetWith(t, 8*3, endian, arr)
+			var buf bytes.Buffer
+			n, err := b.WriteTo(&buf)
+			if err != nil {
+				t.Fatalf("failed to write %v", err)
+			}
+			if n != binaryHeaderSize+8*3 || n != int64(buf.Len()) {
+				t.Errorf("written bytes %v, buffer %v, expected %v", n, buf.Len(), binaryHeaderSize+8*3)
+			}
+			data, _ := b.MarshalBinary()
+			if !bytes.Equal(buf.Bytes(), data) {
+				t.Errorf("written bytes does not match MarshalBinary")
+			}
+
+			var decoded BitSet
+			n, err = decoded.ReadFrom(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("failed to read %v", err)
+			}
+			if n != int64(len(data)) {
+				t.Errorf("read bytes %v, expected %v", n, len(data))
+			}
+			if decoded.order() != endian {
+				t.Errorf("decoded byte order %v, expected %v", decoded.order(), endian)
+			}
+			assertBits(t, &decoded, arr)
+
+			for _, size := range []int{0, 10, len(data) - 1} {
+				n, err := decoded.ReadFrom(bytes.NewReader(data[:size]))
+				if err != io.ErrUnexpectedEOF {
+					t.Errorf("short read %v err : %v, expected err : %v", size, err, io.ErrUnexpectedEOF)
+				}
+				if n != int64(size) {
+					t.Errorf("short read %v read bytes %v", size, n)
+				}
+			}
+			assertBits(t, &decoded, arr)
+		})
+	}
+}
+
+func TestReadBitSet(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			arr := []uint{0, 1, 3, 6, 10, 64, 127, 191}
+			b := newBitSetWith(t, 8*3, endian, arr)
+			if !b.SetBitLen(150) {
+				t.Fatalf("failed to set bit len")
+			}
+			data, _ := b.MarshalBinary()
+			decoded, err := ReadBitSet(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("failed to read %v", err)
+			}
+			if decoded.order() != endian || decoded.BitLen() != 150 {
+				t.Errorf("decoded byte order %v, bit len %v", decoded.order(), decoded.BitLen())
+			}
+			assertBits(t, decoded, arr[:len(arr)-1])
+
+			invalid := append([]byte{}, data...)
+			invalid[0] = 0
+			if _, err := ReadBitSet(bytes.NewReader(invalid)); err != ErrInvalidFormat {
+				t.Errorf("bad magic err : %v, expected err : %v", err, ErrInvalidFormat)
+			}
+			invalid = append([]byte{}, data...)
+			invalid[1] = 2
+			if _, err := ReadBitSet(bytes.NewReader(invalid)); err != ErrInvalidEndianness {
+				t.Errorf("bad byte order err : %v, expected err : %v", err, ErrInvalidEndianness)
+			}
+			invalid = append([]byte{}, data[:binaryHeaderSize]...)
+			binary.BigEndian.PutUint64(invalid[2:], DefaultMaxReadWords+1)
+			if _, err := ReadBitSet(bytes.NewReader(invalid)); err != ErrTooLarge {
+				t.Errorf("large header err : %v, expected err : %v", err, ErrTooLarge)
+			}
+			if _, err := ReadBitSet(bytes.NewReader(data[:len(data)-1])); err != io.ErrUnexpectedEOF {
+				t.Errorf("short read err : %v, expected err : %v", err, io.ErrUnexpectedEOF)
+			}
+		})
+	}
+
+	data, _ := newBitSetWith(t, 8*3, binary.BigEndian, nil).MarshalBinary()
+	if _, err := ReadBitSetMax(bytes.NewReader(data), 2); err != ErrTooLarge {
+		t.Errorf("err : %v, expected err : %v", err, ErrTooLarge)
+	}
+	if b, err := ReadBitSetMax(bytes.NewReader(data), 3); err != nil || b.WordCount() != 3 {
+		t.Errorf("err : %v, expected to read 3 words", err)
+	}
+}
+
+func TestBitSet_MarshalJSON(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			arr := []uint{0, 1, 3, 6, 10, 64, 127}
+			b := newBitSetWith(t, 8*2, endian, arr)
+			data, err := json.Marshal(b)
+			if err != nil {
+				t.Fatalf("failed to marshal %v", err)
+			}
+			var decoded BitSet
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				t.Fatalf("failed to unmarshal %v", err)
+			}
+			if decoded.order() != endian || decoded.BitLen() != b.BitLen() {
+				t.Errorf("decoded %v, %v bits, expected %v, %v bits", decoded.order(), decoded.BitLen(), endian, b.BitLen())
+			}
+			assertBits(t, &decoded, arr)
+		})
+	}
+
+	t.Run("decode each byte order", func(t *testing.T) {
+		tests := map[string]string{
+			"LittleEndian": `{"length":128,"order":"LittleEndian","vector":"SwQAAAAAAAABAAAAAAAAgA=="}`,
+			"BigEndian":    `{"length":128,"order":"BigEndian","vector":"AAAAAAAABEuAAAAAAAAAAQ=="}`,
+		}
+		for name, data := range tests {
+			var decoded BitSet
+			if err := json.Unmarshal([]byte(data), &decoded); err != nil {
+				t.Fatalf("%v : failed to unmarshal %v", name, err)
+			}
+			assertBits(t, &decoded, []uint{0, 1, 3, 6, 10, 64, 127})
+		}
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		tests := map[string]struct {
+			data string
+			err  error
+		}{
+			"invalid order":  {data: `{"length":0,"order":"MiddleEndian","vector":""}`, err: ErrInvalidEndianness},
+			"invalid vector": {data: `{"length":0,"order":"BigEndian","vector":"AAAA"}`, err: ErrInvalidFormat},
+			"invalid length": {data: `{"length":65,"order":"BigEndian","vector":"AAAAAAAAAAA="}`, err: ErrInvalidFormat},
+		}
+		for name, test := range tests {
+			var decoded BitSet
+			if err := json.Unmarshal([]byte(test.data), &decoded); err != test.err {
+				t.Errorf("%v err : %v, expected err : %v", name, err, test.err)
+			}
+		}
+	})
+}
+
+func TestBitSet_GobEncode(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			arr := []uint{0, 1, 3, 6, 10, 64, 127}
+			b := newBitSetWith(t, 8*2, endian, arr)
+			var buf bytes.Buffer
+			if err := gob.NewEncoder(&buf).Encode(b); err != nil {
+				t.Fatalf("failed to encode %v", err)
+			}
+			decoded := newBitSetWith(t, 8, hostEndian, nil)
+			if err := gob.NewDecoder(&buf).Decode(decoded); err != nil {
+				t.Fatalf("failed to decode %v", err)
+			}
+			if decoded.order() != endian {
+				t.Errorf("decoded byte order %v, expected %v", decoded.order(), endian)
+			}
+			for i := uint(0); i < b.BitLen(); i++ {
+				if decoded.Get(i) != b.Get(i) {
+					t.Errorf("decoded get(%v) == %v, expected %v", i, decoded.Get(i), b.Get(i))
+				}
+			}
+		})
+	}
+}
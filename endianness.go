@@ -1,6 +1,7 @@
 package bitset
 
 import (
+	"strings"
 	"unsafe"
 	"encoding/binary"
 )
@@ -24,3 +25,33 @@ func init() {
 func HostEndian() binary.ByteOrder {
 	return hostEndian
 }
+
+// ParseEndian returns the byte order named s.
+// s is "little", "big", or String() of binary.LittleEndian or binary.BigEndian
+// ignoring case.
+func ParseEndian(s string) (binary.ByteOrder, error) {
+	switch strings.ToLower(s) {
+	case "little", strings.ToLower(binary.LittleEndian.String()):
+		return binary.LittleEndian, nil
+	case "big", strings.ToLower(binary.BigEndian.String()):
+		return binary.BigEndian, nil
+	}
+	return nil, ErrInvalidEndianness
+}
+
+// ConvertTo rewrites stored words of b in order.
+// bits of b are not changed while Bytes() reflects the new byte order.
+func (b *BitSet) ConvertTo(order binary.ByteOrder) error {
+	if err := checkOrder(order); err != nil {
+		return err
+	}
+	swap := order != hostEndian
+	if swap == b.swap {
+		return nil
+	}
+	for i, v := range b.vec {
+		b.vec[i] = swapUint64(v)
+	}
+	b.swap = swap
+	return nil
+}
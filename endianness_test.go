@@ -11,3 +11,89 @@ func TestHostOrder(t *testing.T) {
 		t.Errorf("unknown endian %v", hostEndian)
 	}
 }
+
+func TestBitSet_Swapped(t *testing.T) {
+	for _, endian := range endians {
+		b, err := New(make([]byte, 8), endian, false)
+		if err != nil {
+			t.Fatalf("failed to create bit vec %v", err)
+		}
+		if expected := endian != HostEndian(); b.Swapped() != expected {
+			t.Errorf("%v : swapped == %v, expected %v", endian, b.Swapped(), expected)
+		}
+	}
+}
+
+func TestParseEndian(t *testing.T) {
+	tests := map[string]struct {
+		order binary.ByteOrder
+		err   error
+	}{
+		"little":       {order: binary.LittleEndian},
+		"LittleEndian": {order: binary.LittleEndian},
+		"BIG":          {order: binary.BigEndian},
+		"BigEndian":    {order: binary.BigEndian},
+		"middle":       {err: ErrInvalidEndianness},
+		"":             {err: ErrInvalidEndianness},
+	}
+	for s, test := range tests {
+		order, err := ParseEndian(s)
+		if order != test.order || err != test.err {
+			t.Errorf("%q : order : %v, err : %v, expected order : %v, err : %v", s, order, err, test.order, test.err)
+		}
+	}
+	for _, endian := range endians {
+		if order, err := ParseEndian(endian.String()); order != endian || err != nil {
+			t.Errorf("failed to parse %v err : %v", endian, err)
+		}
+	}
+}
+
+func TestBitSet_ConvertTo(t *testing.T) {
+	for _, from := range endians {
+		for _, to := range endians {
+			t.Run(from.String()+"_"+to.String(), func(t *testing.T) {
+				arr := []uint{0, 1, 3, 6, 10, 64, 127}
+				buf := make([]byte, 16)
+				b, err := New(buf, from, false)
+				if err != nil {
+					t.Fatalf("failed to create bit vec %v", err)
+				}
+				for _, v := range arr {
+					if !b.Set(v) {
+						t.Errorf("failed to set %v", v)
+					}
+				}
+				if err := b.ConvertTo(to); err != nil {
+					t.Fatalf("failed to convert %v", err)
+				}
+				for i := uint(0); i < 16*8; i++ {
+					expected := false
+					for _, v := range arr {
+						expected = expected || v == i
+					}
+					if b.Get(i) != expected {
+						t.Errorf("get(%v) == %v after convert, expected %v", i, b.Get(i), expected)
+					}
+				}
+				expected, err := New(make([]byte, 16), to, false)
+				if err != nil {
+					t.Fatalf("failed to create bit vec %v", err)
+				}
+				for _, v := range arr {
+					expected.Set(v)
+				}
+				for i, v := range expected.Bytes() {
+					if buf[i] != v {
+						t.Errorf("converted bytes not match i : %v, v : %v, expected %v", i, buf[i], v)
+					}
+				}
+			})
+		}
+	}
+
+	b, _ := New(make([]byte, 8), HostEndian(), false)
+	if err := b.ConvertTo(nil); err != ErrInvalidEndianness {
+		t.Errorf("err : %v, expected err : %v", err, ErrInvalidEndianness)
+	}
+}
@@ -0,0 +1,44 @@
+//go:build go1.18
+// +build go1.18
+
+package bitset
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func FuzzNew_Validate(f *testing.F) {
+	f.Add([]byte{}, false)
+	f.Add([]byte{75, 4, 0, 0, 0, 0, 0, 0}, false)
+	f.Add([]byte{0, 0, 0, 0, 0, 0, 4, 75, 128, 0, 0, 0, 0, 0, 0, 1}, true)
+	b, _ := New(make([]byte, 16), binary.BigEndian, false)
+	data, _ := b.MarshalBinary()
+	f.Add(data, true)
+
+	f.Fuzz(func(t *testing.T, data []byte, big bool) {
+		var order binary.ByteOrder = binary.LittleEndian
+		if big {
+			order = binary.BigEndian
+		}
+		if b, err := New(data, order, false); err == nil {
+			if err := b.Validate(); err != nil {
+				t.Errorf("invalid vector from New %v", err)
+			}
+			b.Count()
+		}
+		if b, err := NewPadded(data, order, false); err == nil {
+			if err := b.Validate(); err != nil {
+				t.Errorf("invalid vector from NewPadded %v", err)
+			}
+			b.FindFirstZero(0)
+		}
+		var decoded BitSet
+		if err := decoded.UnmarshalBinary(data); err == nil {
+			if err := decoded.Validate(); err != nil {
+				t.Errorf("invalid vector from UnmarshalBinary %v", err)
+			}
+			decoded.FindFirstZero(0)
+		}
+	})
+}
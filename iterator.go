@@ -0,0 +1,262 @@
+package bitset
+
+import (
+	"math/bits"
+)
+
+// SetIterator iterates set bits of BitSet in ascending order.
+// modification of the word under iteration is not reflected.
+type SetIterator struct {
+	b    *BitSet
+	idx  int
+	base uint
+	v    uint64
+}
+
+// Iterator create *SetIterator from the first bit.
+func (b *BitSet) Iterator() *SetIterator {
+	return &SetIterator{b: b}
+}
+
+// Next returns next 1 bit index and true.
+// if not found then returns false
+func (it *SetIterator) Next() (uint, bool) {
+	for it.v == 0 {
+		if it.idx >= len(it.b.vec) {
+			return 0, false
+		}
+		it.v = it.b.conv(it.b.vec[it.idx])
+		it.base = uint(it.idx) * wordBits
+		it.idx++
+	}
+	i := it.base + uint(bits.TrailingZeros64(it.v))
+	it.v &= it.v - 1
+	return i, true
+}
+
+// ReverseSetIterator iterates set bits of BitSet in descending order.
+// modification of the word under iteration is not reflected.
+type ReverseSetIterator struct {
+	b    *BitSet
+	idx  int
+	base uint
+	v    uint64
+}
+
+// ReverseIterator create *ReverseSetIterator from the last bit.
+func (b *BitSet) ReverseIterator() *ReverseSetIterator {
+	return &ReverseSetIterator{b: b, idx: len(b.vec)}
+}
+
+// Next returns next 1 bit index in descending order and true.
+// if not found then returns false
+func (it *ReverseSetIterator) Next() (uint, bool) {
+	for it.v == 0 {
+		if it.idx <= 0 {
+			return 0, false
+		}
+		it.idx--
+		it.v = it.b.conv(it.b.vec[it.idx])
+		it.base = uint(it.idx) * wordBits
+	}
+	j := uint(wordBits - 1 - bits.LeadingZeros64(it.v))
+	it.v &^= 1 << j
+	return it.base + j, true
+}
+
+// ForEachSet calls fn with each 1 bit index in ascending order.
+// if fn returns false then stops iteration.
+func (b *BitSet) ForEachSet(fn func(i uint) bool) {
+	for idx, v := range b.vec {
+		if v == 0 {
+			continue
+		}
+		v = b.conv(v)
+		base := uint(idx) * wordBits
+		for v != 0 {
+			if !fn(base + uint(bits.TrailingZeros64(v))) {
+				return
+			}
+			v &= v - 1
+		}
+	}
+}
+
+// SetIndices returns 1 bit indexes under BitLen() in ascending order.
+func (b *BitSet) SetIndices() []uint {
+	indices := make([]uint, 0, b.CountRange(0, b.length))
+	for idx, v := range b.vec {
+		if v == 0 {
+			continue
+		}
+		v = b.conv(v)
+		base := uint(idx) * wordBits
+		for v != 0 {
+			i := base + uint(bits.TrailingZeros64(v))
+			if i >= b.length {
+				return indices
+			}
+			indices = append(indices, i)
+			v &= v - 1
+		}
+	}
+	return indices
+}
+
+// ForEachDiff calls fn with each index of bits different between b and other in ascending order
+// and whether the bit is set in b. if fn returns false then stops iteration.
+func (b *BitSet) ForEachDiff(other *BitSet, fn func(i uint, inB bool) bool) error {
+	if len(b.vec) != len(other.vec) {
+		return ErrLengthMismatch
+	}
+	for idx, v := range b.vec {
+		if v == other.vec[idx] && b.swap == other.swap {
+			continue
+		}
+		v = b.conv(v)
+		diff := v ^ other.conv(other.vec[idx])
+		base := uint(idx) * wordBits
+		for diff != 0 {
+			j := uint(bits.TrailingZeros64(diff))
+			if !fn(base+j, v&(1<<j) != 0) {
+				return nil
+			}
+			diff &= diff - 1
+		}
+	}
+	return nil
+}
+
+// Each2 calls fn with each index of bits set in a or b in ascending order
+// and whether the bit is set in a and b. if fn returns false then stops iteration.
+func Each2(a, b *BitSet, fn func(i uint, inA, inB bool) bool) error {
+	if len(a.vec) != len(b.vec) {
+		return ErrLengthMismatch
+	}
+	for idx := range a.vec {
+		if a.vec[idx] == 0 && b.vec[idx] == 0 {
+			continue
+		}
+		va, vb := a.conv(a.vec[idx]), b.conv(b.vec[idx])
+		base := uint(idx) * wordBits
+		for v := va | vb; v != 0; v &= v - 1 {
+			j := uint(bits.TrailingZeros64(v))
+			if !fn(base+j, va&(1<<j) != 0, vb&(1<<j) != 0) {
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// NextSetMany fills buf with 1 bit indexes from i in ascending order
+// and returns the index to resume from and the filled part of buf.
+// if no more bit is found then returned slice is empty.
+func (b *BitSet) NextSetMany(i uint, buf []uint) (uint, []uint) {
+	idx := int(i >> log2WordSize)
+	if idx >= len(b.vec) || len(buf) == 0 {
+		return i, buf[:0]
+	}
+	n := 0
+	v := b.conv(b.vec[idx]) & (allBits << (i & (wordBits - 1)))
+	for {
+		base := uint(idx) * wordBits
+		for v != 0 {
+			j := base + uint(bits.TrailingZeros64(v))
+			buf[n] = j
+			n++
+			if n == len(buf) {
+				return j + 1, buf
+			}
+			v &= v - 1
+		}
+		idx++
+		if idx >= len(b.vec) {
+			return uint(idx) * wordBits, buf[:n]
+		}
+		v = b.conv(b.vec[idx])
+	}
+}
+
+// NextZeroMany fills buf with 0 bit indexes from i under BitLen() in ascending order
+// and returns the index to resume from and the filled part of buf.
+// if no more bit is found then returned slice is empty.
+func (b *BitSet) NextZeroMany(i uint, buf []uint) (uint, []uint) {
+	if i >= b.length || len(buf) == 0 {
+		return i, buf[:0]
+	}
+	idx, last := int(i>>log2WordSize), int((b.length-1)>>log2WordSize)
+	n := 0
+	v := ^b.conv(b.vec[idx]) & (allBits << (i & (wordBits - 1)))
+	for {
+		if idx == last && b.length&(wordBits-1) != 0 {
+			v &^= allBits << (b.length & (wordBits - 1))
+		}
+		base := uint(idx) * wordBits
+		for v != 0 {
+			j := base + uint(bits.TrailingZeros64(v))
+			buf[n] = j
+			n++
+			if n == len(buf) {
+				return j + 1, buf
+			}
+			v &= v - 1
+		}
+		idx++
+		if idx > last {
+			return b.length, buf[:n]
+		}
+		v = ^b.conv(b.vec[idx])
+	}
+}
+
+// Drain calls fn with each 1 bit index in ascending order after setting 0 to it.
+// the vector is empty after Drain returns.
+func (b *BitSet) Drain(fn func(i uint)) {
+	for idx, v := range b.vec {
+		if v == 0 {
+			continue
+		}
+		v = b.conv(v)
+		base := uint(idx) * wordBits
+		for v != 0 {
+			i := base + uint(bits.TrailingZeros64(v))
+			b.vec[idx] &^= b.bitMask(i)
+			if b.cached {
+				b.count--
+			}
+			fn(i)
+			v &= v - 1
+		}
+	}
+}
+
+// ForEachZero calls fn with each 0 bit index from start in ascending order.
+// if fn returns false then stops iteration.
+// padding bits after BitLen() are not iterated.
+func (b *BitSet) ForEachZero(start uint, fn func(i uint) bool) {
+	if start >= b.length {
+		return
+	}
+	first, last, head, tail := rangeWords(start, b.length)
+	for idx := first; idx <= last; idx++ {
+		v := b.vec[idx]
+		if v == allBits {
+			continue
+		}
+		v = ^b.conv(v)
+		if idx == first {
+			v &= head
+		}
+		if idx == last {
+			v &= tail
+		}
+		base := uint(idx) * wordBits
+		for v != 0 {
+			if !fn(base + uint(bits.TrailingZeros64(v))) {
+				return
+			}
+			v &= v - 1
+		}
+	}
+}
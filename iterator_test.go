@@ -0,0 +1,435 @@
+package bitset
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestSetIterator_Next(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			t.Run("success to iterate", func(t *testing.T) {
+				arr := []uint{0, 1, 3, 6, 10, 63, 64, 127, 191}
+				b := newBitSetWith(t, 8*3, endian, arr)
+				it := b.Iterator()
+				for _, expected := range arr {
+					result, ok := it.Next()
+					if !ok {
+						t.Fatalf("failed to iterate expected : %v", expected)
+					}
+					if result != expected {
+						t.Errorf("iterated value does not match result : %v, expected : %v", result, expected)
+					}
+				}
+				if result, ok := it.Next(); ok {
+					t.Errorf("unexpectedly iterated value result : %v", result)
+				}
+			})
+
+			t.Run("all bit is 0", func(t *testing.T) {
+				b := newBitSetWith(t, 8*3, endian, nil)
+				if result, ok := b.Iterator().Next(); ok {
+					t.Errorf("unexpectedly iterated value result : %v", result)
+				}
+			})
+		})
+	}
+}
+
+func TestReverseSetIterator_Next(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			t.Run("success to iterate", func(t *testing.T) {
+				arr := []uint{191, 127, 64, 63, 10, 6, 3, 1, 0}
+				b := newBitSetWith(t, 8*3, endian, arr)
+				it := b.ReverseIterator()
+				for _, expected := range arr {
+					result, ok := it.Next()
+					if !ok {
+						t.Fatalf("failed to iterate expected : %v", expected)
+					}
+					if result != expected {
+						t.Errorf("iterated value does not match result : %v, expected : %v", result, expected)
+					}
+				}
+				if result, ok := it.Next(); ok {
+					t.Errorf("unexpectedly iterated value result : %v", result)
+				}
+			})
+
+			t.Run("all bit is 0", func(t *testing.T) {
+				b := newBitSetWith(t, 8*3, endian, nil)
+				if result, ok := b.ReverseIterator().Next(); ok {
+					t.Errorf("unexpectedly iterated value result : %v", result)
+				}
+			})
+		})
+	}
+}
+
+func TestBitSet_ForEachSet(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			t.Run("success to iterate", func(t *testing.T) {
+				arr := []uint{0, 1, 3, 6, 10, 63, 64, 127, 191}
+				b := newBitSetWith(t, 8*3, endian, arr)
+				var result []uint
+				b.ForEachSet(func(i uint) bool {
+					result = append(result, i)
+					return true
+				})
+				if len(result) != len(arr) {
+					t.Fatalf("iterated values %v, expected %v", result, arr)
+				}
+				for i, v := range arr {
+					if result[i] != v {
+						t.Errorf("iterated values %v, expected %v", result, arr)
+					}
+				}
+			})
+
+			t.Run("stop iteration", func(t *testing.T) {
+				b := newBitSetWith(t, 8*3, endian, []uint{0, 1, 64, 127})
+				var result []uint
+				b.ForEachSet(func(i uint) bool {
+					result = append(result, i)
+					return i < 64
+				})
+				if len(result) != 3 || result[2] != 64 {
+					t.Errorf("iterated values %v, expected %v", result, []uint{0, 1, 64})
+				}
+			})
+		})
+	}
+}
+
+func TestEach2(t *testing.T) {
+	for _, e1 := range endians {
+		for _, e2 := range endians {
+			t.Run(e1.String()+"_"+e2.String(), func(t *testing.T) {
+				a := newBitSetWith(t, 8*3, e1, []uint{0, 1, 3, 64, 127})
+				b := newBitSetWith(t, 8*3, e2, []uint{1, 2, 64, 128})
+				type visit struct {
+					i        uint
+					inA, inB bool
+				}
+				expected := []visit{
+					{0, true, false}, {1, true, true}, {2, false, true}, {3, true, false},
+					{64, true, true}, {127, true, false}, {128, false, true},
+				}
+				var result []visit
+				if err := Each2(a, b, func(i uint, inA, inB bool) bool {
+					result = append(result, visit{i, inA, inB})
+					return true
+				}); err != nil {
+					t.Fatalf("failed to iterate %v", err)
+				}
+				if len(result) != len(expected) {
+					t.Fatalf("iterated values %v, expected %v", result, expected)
+				}
+				for i, v := range expected {
+					if result[i] != v {
+						t.Errorf("iterated values %v, expected %v", result, expected)
+					}
+				}
+
+				result = result[:0]
+				Each2(a, b, func(i uint, inA, inB bool) bool {
+					result = append(result, visit{i, inA, inB})
+					return i < 2
+				})
+				if len(result) != 3 || result[2] != expected[2] {
+					t.Errorf("iterated values %v, expected %v", result, expected[:3])
+				}
+				if err := Each2(a, newBitSetWith(t, 8*2, e2, nil), func(uint, bool, bool) bool { return true }); err != ErrLengthMismatch {
+					t.Errorf("err == %v, expected %v", err, ErrLengthMismatch)
+				}
+			})
+		}
+	}
+}
+
+func TestBitSet_NextSetMany(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			arr := []uint{0, 1, 3, 6, 10, 63, 64, 127, 191}
+			b := newBitSetWith(t, 8*3, endian, arr)
+			for _, size := range []int{1, 2, 4, len(arr), len(arr) + 1} {
+				var (
+					result []uint
+					found  []uint
+					i      uint
+				)
+				buf := make([]uint, size)
+				for i, found = b.NextSetMany(0, buf); len(found) > 0; i, found = b.NextSetMany(i, buf) {
+					result = append(result, found...)
+				}
+				if len(result) != len(arr) {
+					t.Fatalf("buffer size %v : found values %v, expected %v", size, result, arr)
+				}
+				for j, v := range arr {
+					if result[j] != v {
+						t.Errorf("buffer size %v : found values %v, expected %v", size, result, arr)
+					}
+				}
+			}
+			if _, found := b.NextSetMany(4, make([]uint, 2)); len(found) != 2 || found[0] != 6 || found[1] != 10 {
+				t.Errorf("found values %v from 4, expected %v", found, []uint{6, 10})
+			}
+			if _, found := b.NextSetMany(8*3*8, make([]uint, 2)); len(found) != 0 {
+				t.Errorf("unexpectedly found values %v", found)
+			}
+		})
+	}
+}
+
+func TestBitSet_NextZeroMany(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			arr := []uint{0, 1, 3, 6, 10, 63, 64, 127, 149, 170}
+			b := newBitSetWith(t, 8*3, endian, nil)
+			b.SetAll()
+			for _, v := range arr {
+				b.Unset(v)
+			}
+			if !b.SetBitLen(150) {
+				t.Fatalf("failed to set bit len")
+			}
+			expected := arr[:9]
+			for _, size := range []int{1, 2, 4, len(expected), len(expected) + 1} {
+				var (
+					result []uint
+					found  []uint
+					i      uint
+				)
+				buf := make([]uint, size)
+				for i, found = b.NextZeroMany(0, buf); len(found) > 0; i, found = b.NextZeroMany(i, buf) {
+					result = append(result, found...)
+				}
+				if len(result) != len(expected) {
+					t.Fatalf("buffer size %v : found values %v, expected %v", size, result, expected)
+				}
+				for j, v := range expected {
+					if result[j] != v {
+						t.Errorf("buffer size %v : found values %v, expected %v", size, result, expected)
+					}
+				}
+			}
+			if _, found := b.NextZeroMany(4, make([]uint, 2)); len(found) != 2 || found[0] != 6 || found[1] != 10 {
+				t.Errorf("found values %v from 4, expected %v", found, []uint{6, 10})
+			}
+			if _, found := b.NextZeroMany(150, make([]uint, 2)); len(found) != 0 {
+				t.Errorf("unexpectedly found values %v in padding", found)
+			}
+		})
+	}
+}
+
+func TestBitSet_Drain(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			arr := []uint{0, 1, 3, 6, 10, 63, 64, 127, 191}
+			b := newBitSetWith(t, 8*3, endian, arr)
+			var result []uint
+			b.Drain(func(i uint) {
+				if b.Get(i) {
+					t.Errorf("bit %v is not unset before callback", i)
+				}
+				result = append(result, i)
+			})
+			if len(result) != len(arr) {
+				t.Fatalf("drained values %v, expected %v", result, arr)
+			}
+			for i, v := range arr {
+				if result[i] != v {
+					t.Errorf("drained values %v, expected %v", result, arr)
+				}
+			}
+			if !b.None() {
+				t.Errorf("vector is not empty after drain %v", b)
+			}
+		})
+	}
+}
+
+func TestBitSet_ForEachZero(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			b := newBitSetWith(t, 8*3, endian, nil)
+			b.SetAll()
+			arr := []uint{0, 1, 3, 6, 10, 63, 64, 127, 140, 160}
+			for _, v := range arr {
+				if !b.Unset(v) {
+					t.Errorf("failed to unset %v", v)
+				}
+			}
+			if !b.SetBitLen(150) {
+				t.Fatalf("failed to set bit len")
+			}
+			var result []uint
+			b.ForEachZero(2, func(i uint) bool {
+				result = append(result, i)
+				return true
+			})
+			expected := arr[2:9]
+			if len(result) != len(expected) {
+				t.Fatalf("iterated values %v, expected %v", result, expected)
+			}
+			for i, v := range expected {
+				if result[i] != v {
+					t.Errorf("iterated values %v, expected %v", result, expected)
+				}
+			}
+
+			result = result[:0]
+			b.ForEachZero(0, func(i uint) bool {
+				result = append(result, i)
+				return i < 6
+			})
+			if len(result) != 4 || result[3] != 6 {
+				t.Errorf("iterated values %v, expected %v", result, arr[:4])
+			}
+			b.ForEachZero(150, func(i uint) bool {
+				t.Errorf("unexpectedly iterated padding bit %v", i)
+				return true
+			})
+		})
+	}
+}
+
+func TestBitSet_SetIndices(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			arr := []uint{0, 1, 3, 6, 10, 63, 64, 127, 149, 150, 191}
+			b := newBitSetWith(t, 8*3, endian, arr)
+			if !b.SetBitLen(150) {
+				t.Fatalf("failed to set bit len")
+			}
+			result := b.SetIndices()
+			expected := arr[:9]
+			if len(result) != len(expected) {
+				t.Fatalf("indices %v, expected %v", result, expected)
+			}
+			for i, v := range expected {
+				if result[i] != v {
+					t.Errorf("indices %v, expected %v", result, expected)
+				}
+			}
+			if result := newBitSetWith(t, 8*3, endian, nil).SetIndices(); len(result) != 0 {
+				t.Errorf("indices of empty vector %v", result)
+			}
+		})
+	}
+}
+
+func TestBitSet_ForEachDiff(t *testing.T) {
+	for _, e1 := range endians {
+		for _, e2 := range endians {
+			t.Run(e1.String()+"_"+e2.String(), func(t *testing.T) {
+				b := newBitSetWith(t, 8*3, e1, []uint{0, 1, 3, 6, 10, 64, 127})
+				other := newBitSetWith(t, 8*3, e2, []uint{1, 2, 6, 11, 64, 128})
+				var result []uint
+				var inB []bool
+				err := b.ForEachDiff(other, func(i uint, in bool) bool {
+					result = append(result, i)
+					inB = append(inB, in)
+					return true
+				})
+				if err != nil {
+					t.Fatalf("failed to iterate diff %v", err)
+				}
+				expected := []uint{0, 2, 3, 10, 11, 127, 128}
+				expectedInB := []bool{true, false, true, true, false, true, false}
+				if len(result) != len(expected) {
+					t.Fatalf("iterated values %v, expected %v", result, expected)
+				}
+				for i, v := range expected {
+					if result[i] != v || inB[i] != expectedInB[i] {
+						t.Errorf("iterated values %v %v, expected %v %v", result, inB, expected, expectedInB)
+					}
+				}
+
+				result = result[:0]
+				b.ForEachDiff(other, func(i uint, _ bool) bool {
+					result = append(result, i)
+					return i < 3
+				})
+				if len(result) != 3 || result[2] != 3 {
+					t.Errorf("iterated values %v, expected %v", result, expected[:3])
+				}
+				if err := b.ForEachDiff(newBitSetWith(t, 8*2, e2, nil), func(uint, bool) bool { return true }); err != ErrLengthMismatch {
+					t.Errorf("err == %v, expected %v", err, ErrLengthMismatch)
+				}
+			})
+		}
+	}
+}
+
+func TestBitSet_AscendingOrder(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	patterns := []struct {
+		name string
+		set  func(i uint) bool
+	}{
+		{name: "sparse", set: func(uint) bool { return rnd.Intn(97) == 0 }},
+		{name: "dense", set: func(uint) bool { return rnd.Intn(8) != 0 }},
+		{name: "half", set: func(uint) bool { return rnd.Intn(2) == 0 }},
+		{name: "byte edge", set: func(i uint) bool { return i%8 == 0 || i%8 == 7 }},
+		{name: "word edge", set: func(i uint) bool { return i%64 == 0 || i%64 == 63 }},
+		{name: "odd bytes", set: func(i uint) bool { return i/8%2 == 1 }},
+		{name: "single byte", set: func(i uint) bool { return i/8%8 == 3 }},
+	}
+	for _, endian := range endians {
+		for _, pattern := range patterns {
+			t.Run(endian.String()+"_"+pattern.name, func(t *testing.T) {
+				for n := 0; n < 20; n++ {
+					size := 8 * (1 + rnd.Intn(16))
+					b := newBitSetWith(t, size, endian, nil)
+					var expected []uint
+					for i := uint(0); i < uint(size)*8; i++ {
+						if pattern.set(i) {
+							b.Set(i)
+							expected = append(expected, i)
+						}
+					}
+
+					var each []uint
+					b.ForEachSet(func(i uint) bool {
+						each = append(each, i)
+						return true
+					})
+					var iterated []uint
+					it := b.Iterator()
+					for i, ok := it.Next(); ok; i, ok = it.Next() {
+						iterated = append(iterated, i)
+					}
+					var found []uint
+					for i, ok := b.FindFirstOne(0); ok; i, ok = b.FindFirstOne(i + 1) {
+						found = append(found, i)
+					}
+					var reversed []uint
+					rit := b.ReverseIterator()
+					for i, ok := rit.Next(); ok; i, ok = rit.Next() {
+						reversed = append([]uint{i}, reversed...)
+					}
+					for method, result := range map[string][]uint{
+						"ForEachSet":      each,
+						"SetIterator":     iterated,
+						"FindFirstOne":    found,
+						"ReverseIterator": reversed,
+						"SetIndices":      b.SetIndices(),
+					} {
+						if len(result) != len(expected) {
+							t.Fatalf("%v : %v indexes, expected %v", method, len(result), len(expected))
+						}
+						for i, v := range expected {
+							if result[i] != v {
+								t.Fatalf("%v : %v-th index == %v, expected %v", method, i, result[i], v)
+							}
+						}
+					}
+				}
+			})
+		}
+	}
+}
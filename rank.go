@@ -0,0 +1,106 @@
+package bitset
+
+import (
+	"math/bits"
+)
+
+// Rank returns the number of set bits in [0, i).
+// i is clamped to BitLen().
+func (b *BitSet) Rank(i uint) uint {
+	if i > b.length {
+		i = b.length
+	}
+	return b.CountRange(0, i)
+}
+
+// RankIndex holds cumulative set bit counts of each 512 bits block of BitSet to answer Rank in constant time.
+// RankIndex is not updated by modification of the vector, so BuildRankIndex again after modification.
+type RankIndex struct {
+	b      *BitSet
+	length uint
+	blocks []uint
+}
+
+const (
+	// log2BlockSize is log2 of the number of bits in a block of RankIndex.
+	log2BlockSize = 9
+	blockWords    = 1 << (log2BlockSize - log2WordSize)
+)
+
+// BuildRankIndex creates *RankIndex of the current bits of b.
+func (b *BitSet) BuildRankIndex() *RankIndex {
+	blocks := make([]uint, b.length>>log2BlockSize+1)
+	for k := 1; k < len(blocks); k++ {
+		blocks[k] = blocks[k-1] + countWords(b.vec[(k-1)*blockWords:k*blockWords])
+	}
+	return &RankIndex{b: b, length: b.length, blocks: blocks}
+}
+
+// Rank returns the number of set bits in [0, i) as BitSet.Rank.
+// i is clamped to BitLen() at BuildRankIndex and the current BitLen().
+func (ri *RankIndex) Rank(i uint) uint {
+	if i > ri.length {
+		i = ri.length
+	}
+	if i > ri.b.length {
+		i = ri.b.length
+	}
+	cnt := ri.blocks[i>>log2BlockSize]
+	idx := int(i >> log2WordSize)
+	for w := int(i>>log2BlockSize) * blockWords; w < idx; w++ {
+		cnt += uint(bits.OnesCount64(ri.b.vec[w]))
+	}
+	if offset := i & (wordBits - 1); offset != 0 {
+		cnt += uint(bits.OnesCount64(ri.b.vec[idx] & ri.b.conv(^(allBits << offset))))
+	}
+	return cnt
+}
+
+// Select returns the index of n-th (0-based) set bit and true.
+// if the number of set bits is not greater than n then returns false.
+func (b *BitSet) Select(n uint) (uint, bool) {
+	for idx, v := range b.vec {
+		cnt := uint(bits.OnesCount64(v))
+		if n >= cnt {
+			n -= cnt
+			continue
+		}
+		i := uint(idx)*wordBits + selectInWord(b.conv(v), n)
+		if i >= b.length {
+			return 0, false
+		}
+		return i, true
+	}
+	return 0, false
+}
+
+// SelectZero returns the index of n-th (0-based) 0 bit under BitLen() and true.
+// if the number of 0 bits is not greater than n then returns false.
+func (b *BitSet) SelectZero(n uint) (uint, bool) {
+	if b.length == 0 {
+		return 0, false
+	}
+	last := int((b.length - 1) >> log2WordSize)
+	for idx, v := range b.vec[:last+1] {
+		v = ^b.conv(v)
+		if idx == last && b.length&(wordBits-1) != 0 {
+			v &^= allBits << (b.length & (wordBits - 1))
+		}
+		cnt := uint(bits.OnesCount64(v))
+		if n >= cnt {
+			n -= cnt
+			continue
+		}
+		return uint(idx)*wordBits + selectInWord(v, n), true
+	}
+	return 0, false
+}
+
+// selectInWord returns the position of n-th set bit in v.
+// v must have more than n set bits.
+func selectInWord(v uint64, n uint) uint {
+	for ; n > 0; n-- {
+		v &= v - 1
+	}
+	return uint(bits.TrailingZeros64(v))
+}
@@ -0,0 +1,160 @@
+package bitset
+
+import (
+	"testing"
+)
+
+func TestBitSet_Rank(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			b := newBitSetWith(t, 8*3, endian, []uint{0, 1, 3, 63, 64, 127, 128, 191})
+			tests := []struct {
+				i, expected uint
+			}{
+				{i: 0, expected: 0},
+				{i: 1, expected: 1},
+				{i: 4, expected: 3},
+				{i: 63, expected: 3},
+				{i: 64, expected: 4},
+				{i: 65, expected: 5},
+				{i: 128, expected: 6},
+				{i: 129, expected: 7},
+				{i: 191, expected: 7},
+				{i: 192, expected: 8},
+				{i: 1000, expected: 8},
+			}
+			for _, test := range tests {
+				if result := b.Rank(test.i); result != test.expected {
+					t.Errorf("rank(%v) == %v, expected %v", test.i, result, test.expected)
+				}
+			}
+			if !b.SetBitLen(100) || b.Rank(1000) != 5 {
+				t.Errorf("rank is not clamped to bit len")
+			}
+		})
+	}
+}
+
+func TestRankIndex_Rank(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			b := newBitSetWith(t, 8*3, endian, []uint{0, 1, 3, 63, 64, 127, 128, 150, 191})
+			for _, length := range []uint{8 * 3 * 8, 150, 128, 0} {
+				if !b.SetBitLen(length) {
+					t.Fatalf("failed to set bit len %v", length)
+				}
+				ri := b.BuildRankIndex()
+				for i := uint(0); i <= 8*3*8+1; i++ {
+					if result, expected := ri.Rank(i), b.Rank(i); result != expected {
+						t.Errorf("length %v : rank(%v) == %v, expected %v", length, i, result, expected)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRankIndex_Rank_Blocks(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			b := newBitSetWith(t, 8*20, endian, []uint{0, 63, 511, 512, 513, 1023, 1024, 1200, 1279})
+			for _, length := range []uint{8 * 20 * 8, 1100, 1024, 512, 500} {
+				if !b.SetBitLen(length) {
+					t.Fatalf("failed to set bit len %v", length)
+				}
+				ri := b.BuildRankIndex()
+				for i := uint(0); i <= 8*20*8+1; i++ {
+					if result, expected := ri.Rank(i), b.Rank(i); result != expected {
+						t.Errorf("length %v : rank(%v) == %v, expected %v", length, i, result, expected)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRankIndex_Rank_Grow(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			b, err := New(make([]byte, 8*2), endian, true)
+			if err != nil {
+				t.Fatalf("failed to create bit vec %v", err)
+			}
+			b.Set(3)
+			b.Set(100)
+			ri := b.BuildRankIndex()
+			if !b.Set(1000) {
+				t.Fatalf("failed to extend")
+			}
+			for _, i := range []uint{150, 1001, 2000} {
+				if result := ri.Rank(i); result != 2 {
+					t.Errorf("rank(%v) == %v, expected 2", i, result)
+				}
+			}
+		})
+	}
+}
+
+func TestBitSet_Select(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			arr := []uint{0, 1, 3, 63, 64, 127, 128, 150, 191}
+			b := newBitSetWith(t, 8*3, endian, arr)
+			for n, expected := range arr {
+				result, ok := b.Select(uint(n))
+				if !ok || result != expected {
+					t.Errorf("select(%v) == %v, %v, expected %v, true", n, result, ok, expected)
+				}
+				if rank := b.Rank(result); rank != uint(n) {
+					t.Errorf("rank(select(%v)) == %v", n, rank)
+				}
+			}
+			if result, ok := b.Select(uint(len(arr))); ok {
+				t.Errorf("unexpectedly selected %v", result)
+			}
+			if !b.SetBitLen(150) {
+				t.Fatalf("failed to set bit len")
+			}
+			if result, ok := b.Select(7); ok {
+				t.Errorf("unexpectedly selected padding bit %v", result)
+			}
+		})
+	}
+}
+
+func TestBitSet_SelectZero(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			arr := []uint{0, 1, 3, 63, 64, 127, 128, 149, 191}
+			b := newBitSetWith(t, 8*3, endian, nil)
+			b.SetAll()
+			for _, v := range arr {
+				b.Unset(v)
+			}
+			for n, expected := range arr {
+				result, ok := b.SelectZero(uint(n))
+				if !ok || result != expected {
+					t.Errorf("select zero(%v) == %v, %v, expected %v, true", n, result, ok, expected)
+				}
+			}
+			if result, ok := b.SelectZero(uint(len(arr))); ok {
+				t.Errorf("unexpectedly selected %v", result)
+			}
+			if !b.SetBitLen(150) {
+				t.Fatalf("failed to set bit len")
+			}
+			if result, ok := b.SelectZero(7); !ok || result != 149 {
+				t.Errorf("select zero(7) == %v, %v, expected 149, true", result, ok)
+			}
+			if result, ok := b.SelectZero(8); ok {
+				t.Errorf("unexpectedly selected padding bit %v", result)
+			}
+			if !b.SetBitLen(0) {
+				t.Fatalf("failed to set bit len")
+			}
+			if result, ok := b.SelectZero(0); ok {
+				t.Errorf("unexpectedly selected %v in empty vector", result)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package bitset
+
+// ReadOnlyBitSet is read only view of BitSet.
+// it shares memory with BitSet, so modification of BitSet is visible.
+type ReadOnlyBitSet struct {
+	b *BitSet
+}
+
+// ReadOnly create *ReadOnlyBitSet sharing memory with b.
+func (b *BitSet) ReadOnly() *ReadOnlyBitSet {
+	return &ReadOnlyBitSet{b: b}
+}
+
+// Get checks the bit is set.
+func (r *ReadOnlyBitSet) Get(i uint) bool {
+	return r.b.Get(i)
+}
+
+// BitLen returns the number of meaningful bits.
+func (r *ReadOnlyBitSet) BitLen() uint {
+	return r.b.BitLen()
+}
+
+// InRange checks i is in the vector.
+func (r *ReadOnlyBitSet) InRange(i uint) bool {
+	return r.b.InRange(i)
+}
+
+// WordCount returns the number of words the vector holds.
+func (r *ReadOnlyBitSet) WordCount() int {
+	return r.b.WordCount()
+}
+
+// Word returns i-th word in host byte order.
+func (r *ReadOnlyBitSet) Word(i int) uint64 {
+	return r.b.Word(i)
+}
+
+// Count returns the number of set bit
+func (r *ReadOnlyBitSet) Count() uint {
+	return r.b.Count()
+}
+
+// CountRange returns the number of set bit in [start, end).
+func (r *ReadOnlyBitSet) CountRange(start, end uint) uint {
+	return r.b.CountRange(start, end)
+}
+
+// Any checks any bit is set.
+func (r *ReadOnlyBitSet) Any() bool {
+	return r.b.Any()
+}
+
+// None checks no bit is set.
+func (r *ReadOnlyBitSet) None() bool {
+	return r.b.None()
+}
+
+// All checks all bits are set.
+func (r *ReadOnlyBitSet) All() bool {
+	return r.b.All()
+}
+
+// FindFirstOne returns first 1 bit index from i and true.
+func (r *ReadOnlyBitSet) FindFirstOne(i uint) (uint, bool) {
+	return r.b.FindFirstOne(i)
+}
+
+// FindFirstZero returns first 0 bit index from i and true.
+func (r *ReadOnlyBitSet) FindFirstZero(i uint) (uint, bool) {
+	return r.b.FindFirstZero(i)
+}
+
+// FindLastOne returns last 1 bit index and true.
+func (r *ReadOnlyBitSet) FindLastOne() (uint, bool) {
+	return r.b.FindLastOne()
+}
+
+// FindLastZero returns last 0 bit index and true.
+func (r *ReadOnlyBitSet) FindLastZero() (uint, bool) {
+	return r.b.FindLastZero()
+}
+
+// FindPrevOne returns last 1 bit index at or before i and true.
+func (r *ReadOnlyBitSet) FindPrevOne(i uint) (uint, bool) {
+	return r.b.FindPrevOne(i)
+}
+
+// Rank returns the number of set bits in [0, i).
+func (r *ReadOnlyBitSet) Rank(i uint) uint {
+	return r.b.Rank(i)
+}
+
+// Select returns the index of n-th (0-based) set bit and true.
+func (r *ReadOnlyBitSet) Select(n uint) (uint, bool) {
+	return r.b.Select(n)
+}
+
+// Iterator create *SetIterator from the first bit.
+func (r *ReadOnlyBitSet) Iterator() *SetIterator {
+	return r.b.Iterator()
+}
+
+// ForEachSet calls fn with each 1 bit index in ascending order.
+func (r *ReadOnlyBitSet) ForEachSet(fn func(i uint) bool) {
+	r.b.ForEachSet(fn)
+}
+
+// Clone creates mutable *BitSet holding a copy of the vector.
+func (r *ReadOnlyBitSet) Clone() *BitSet {
+	return r.b.Clone()
+}
+
+// String returns set bit indexes for debugging.
+func (r *ReadOnlyBitSet) String() string {
+	return r.b.String()
+}
@@ -0,0 +1,30 @@
+package bitset
+
+import (
+	"testing"
+)
+
+func TestBitSet_ReadOnly(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			b := newBitSetWith(t, 8*3, endian, []uint{0, 3, 64})
+			r := b.ReadOnly()
+			if !r.Get(3) || r.Count() != 3 || r.BitLen() != 8*3*8 {
+				t.Errorf("read only view does not match %v", r)
+			}
+			if !b.Set(100) || !r.Get(100) {
+				t.Errorf("modification of BitSet is not visible")
+			}
+			if v, ok := r.FindFirstOne(4); !ok || v != 64 {
+				t.Errorf("find first one == %v, %v, expected 64, true", v, ok)
+			}
+			if v, ok := r.FindLastOne(); !ok || v != 100 {
+				t.Errorf("find last one == %v, %v, expected 100, true", v, ok)
+			}
+			c := r.Clone()
+			if !c.Set(5) || r.Get(5) {
+				t.Errorf("clone shares memory with read only view")
+			}
+		})
+	}
+}
@@ -0,0 +1,507 @@
+package bitset
+
+import (
+	"math/bits"
+	"unsafe"
+)
+
+// aliasing checks words of x and y overlap at different positions.
+// word by word operations from such a vector read words already written,
+// so they return ErrAliasingBuffers. vectors sharing the same words are safe.
+func aliasing(x, y []uint64) bool {
+	if len(x) == 0 || len(y) == 0 {
+		return false
+	}
+	px, py := uintptr(unsafe.Pointer(&x[0])), uintptr(unsafe.Pointer(&y[0]))
+	return px != py && px < py+uintptr(len(y))*wordBytes && py < px+uintptr(len(x))*wordBytes
+}
+
+// And sets intersection of b and other to b.
+// result is written in byte order of b.
+func (b *BitSet) And(other *BitSet) error {
+	if len(b.vec) != len(other.vec) {
+		return ErrLengthMismatch
+	}
+	if aliasing(b.vec, other.vec) {
+		return ErrAliasingBuffers
+	}
+	if b.swap == other.swap {
+		for i, v := range other.vec {
+			b.vec[i] &= v
+		}
+	} else {
+		for i, v := range other.vec {
+			b.vec[i] &= swapUint64(v)
+		}
+	}
+	b.recount()
+	return nil
+}
+
+// Or sets union of b and other to b.
+// result is written in byte order of b. bits after BitLen() of b are dropped.
+func (b *BitSet) Or(other *BitSet) error {
+	if len(b.vec) != len(other.vec) {
+		return ErrLengthMismatch
+	}
+	if aliasing(b.vec, other.vec) {
+		return ErrAliasingBuffers
+	}
+	if b.swap == other.swap {
+		for i, v := range other.vec {
+			b.vec[i] |= v
+		}
+	} else {
+		for i, v := range other.vec {
+			b.vec[i] |= swapUint64(v)
+		}
+	}
+	b.clearPadding()
+	b.recount()
+	return nil
+}
+
+// Xor sets symmetric difference of b and other to b.
+// result is written in byte order of b. bits after BitLen() of b are dropped.
+//
+// xor is calculated on stored words directly only if both have the same
+// byte order, because swap(x) ^ swap(y) == swap(x ^ y) but swap(x) ^ y is not.
+func (b *BitSet) Xor(other *BitSet) error {
+	if len(b.vec) != len(other.vec) {
+		return ErrLengthMismatch
+	}
+	if aliasing(b.vec, other.vec) {
+		return ErrAliasingBuffers
+	}
+	if b.swap == other.swap {
+		for i, v := range other.vec {
+			b.vec[i] ^= v
+		}
+	} else {
+		for i, v := range other.vec {
+			b.vec[i] ^= swapUint64(v)
+		}
+	}
+	b.clearPadding()
+	b.recount()
+	return nil
+}
+
+// AndNot sets difference of b and other (b & ^other) to b.
+// result is written in byte order of b.
+func (b *BitSet) AndNot(other *BitSet) error {
+	if len(b.vec) != len(other.vec) {
+		return ErrLengthMismatch
+	}
+	if aliasing(b.vec, other.vec) {
+		return ErrAliasingBuffers
+	}
+	if b.swap == other.swap {
+		for i, v := range other.vec {
+			b.vec[i] &^= v
+		}
+	} else {
+		for i, v := range other.vec {
+			b.vec[i] &^= swapUint64(v)
+		}
+	}
+	b.recount()
+	return nil
+}
+
+// AndNotInto writes difference of b and mask (b & ^mask) to dst in byte order of dst.
+// bits after BitLen() of dst are dropped.
+// b and mask are not modified.
+func (b *BitSet) AndNotInto(dst, mask *BitSet) error {
+	if len(mask.vec) != len(b.vec) || len(dst.vec) != len(b.vec) {
+		return ErrLengthMismatch
+	}
+	if aliasing(dst.vec, b.vec) || aliasing(dst.vec, mask.vec) {
+		return ErrAliasingBuffers
+	}
+	for i := range dst.vec {
+		dst.vec[i] = dst.conv(b.conv(b.vec[i]) &^ mask.conv(mask.vec[i]))
+	}
+	dst.clearPadding()
+	dst.recount()
+	return nil
+}
+
+// IntersectionCount returns the number of bits set both in b and other.
+func (b *BitSet) IntersectionCount(other *BitSet) (uint, error) {
+	if len(b.vec) != len(other.vec) {
+		return 0, ErrLengthMismatch
+	}
+	var cnt uint
+	if b.swap == other.swap {
+		for i, v := range other.vec {
+			cnt += uint(bits.OnesCount64(b.vec[i] & v))
+		}
+	} else {
+		for i, v := range other.vec {
+			cnt += uint(bits.OnesCount64(b.vec[i] & swapUint64(v)))
+		}
+	}
+	return cnt, nil
+}
+
+// DifferenceCount returns the number of bits set in b but not in other.
+func (b *BitSet) DifferenceCount(other *BitSet) (uint, error) {
+	if len(b.vec) != len(other.vec) {
+		return 0, ErrLengthMismatch
+	}
+	var cnt uint
+	if b.swap == other.swap {
+		for i, v := range other.vec {
+			cnt += uint(bits.OnesCount64(b.vec[i] &^ v))
+		}
+	} else {
+		for i, v := range other.vec {
+			cnt += uint(bits.OnesCount64(b.vec[i] &^ swapUint64(v)))
+		}
+	}
+	return cnt, nil
+}
+
+// Equal checks b and other have the same bits regardless of byte order.
+// if lengths differ then extra words of the longer one must be all 0.
+func (b *BitSet) Equal(other *BitSet) bool {
+	short, long := b.vec, other.vec
+	if len(short) > len(long) {
+		short, long = long, short
+	}
+	for _, v := range long[len(short):] {
+		if v != 0 {
+			return false
+		}
+	}
+	if b.swap == other.swap {
+		for i, v := range short {
+			if v != long[i] {
+				return false
+			}
+		}
+	} else {
+		for i, v := range short {
+			if v != swapUint64(long[i]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Compare compares b and other as unsigned integers with bit 0 as the least significant bit
+// and returns -1, 0 or 1. shorter vector is extended with 0, so it returns 0 iff Equal.
+func (b *BitSet) Compare(other *BitSet) int {
+	n := len(b.vec)
+	if len(other.vec) > n {
+		n = len(other.vec)
+	}
+	for i := n - 1; i >= 0; i-- {
+		var x, y uint64
+		if i < len(b.vec) {
+			x = b.conv(b.vec[i])
+		}
+		if i < len(other.vec) {
+			y = other.conv(other.vec[i])
+		}
+		if x < y {
+			return -1
+		} else if x > y {
+			return 1
+		}
+	}
+	return 0
+}
+
+// EqualPrefix checks b and other have the same bits in [0, n) regardless of byte order.
+// if n is over BitLen() of b or other then returns ErrOutOfRange.
+func (b *BitSet) EqualPrefix(other *BitSet, n uint) (bool, error) {
+	if n > b.length || n > other.length {
+		return false, ErrOutOfRange
+	}
+	words := int(n >> log2WordSize)
+	for i, v := range b.vec[:words] {
+		if b.conv(v) != other.conv(other.vec[i]) {
+			return false, nil
+		}
+	}
+	if rest := n & (wordBits - 1); rest != 0 {
+		mask := ^(allBits << rest)
+		if b.conv(b.vec[words])&mask != other.conv(other.vec[words])&mask {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+const (
+	fnvOffset64 = 14695981039346656037
+	fnvPrime64  = 1099511628211
+)
+
+// Hash returns FNV-1a hash of bits of b.
+// vectors which are Equal have the same hash regardless of byte order.
+// it is not cryptographically secure.
+func (b *BitSet) Hash() uint64 {
+	n := len(b.vec)
+	for n > 0 && b.vec[n-1] == 0 {
+		n--
+	}
+	h := uint64(fnvOffset64)
+	for _, v := range b.vec[:n] {
+		v = b.conv(v)
+		// bytes of host words are hashed from lower bits to be stable on any architecture
+		for i := 0; i < wordBytes; i++ {
+			h ^= v & 0xff
+			h *= fnvPrime64
+			v >>= 8
+		}
+	}
+	return h
+}
+
+// HammingDistance returns the number of bits which differ between b and other.
+func (b *BitSet) HammingDistance(other *BitSet) (uint, error) {
+	if len(b.vec) != len(other.vec) {
+		return 0, ErrLengthMismatch
+	}
+	var cnt uint
+	if b.swap == other.swap {
+		for i, v := range other.vec {
+			cnt += uint(bits.OnesCount64(b.vec[i] ^ v))
+		}
+	} else {
+		for i, v := range other.vec {
+			cnt += uint(bits.OnesCount64(b.vec[i] ^ swapUint64(v)))
+		}
+	}
+	return cnt, nil
+}
+
+// CopyFrom copies words of other to b in byte order of b without allocation
+// and returns the number of copied words.
+// if lengths differ then min(len) words are copied. bits after BitLen() of b are dropped.
+// overlapping words are copied correctly in the same byte order,
+// otherwise returns ErrAliasingBuffers.
+func (b *BitSet) CopyFrom(other *BitSet) (uint, error) {
+	if b.swap == other.swap {
+		n := copy(b.vec, other.vec)
+		b.clearPadding()
+		b.recount()
+		return uint(n), nil
+	}
+	n := len(b.vec)
+	if len(other.vec) < n {
+		n = len(other.vec)
+	}
+	if aliasing(b.vec[:n], other.vec[:n]) {
+		return 0, ErrAliasingBuffers
+	}
+	for i, v := range other.vec[:n] {
+		b.vec[i] = swapUint64(v)
+	}
+	b.clearPadding()
+	b.recount()
+	return uint(n), nil
+}
+
+// Append creates *BitSet holding bits of b in [0, b.BitLen()) followed by bits of other in [0, other.BitLen()).
+// created BitSet has byte order of b and does not share memory with b and other.
+func (b *BitSet) Append(other *BitSet) *BitSet {
+	length := b.length + other.length
+	nb := &BitSet{
+		vec:    make([]uint64, (length+wordBits-1)/wordBits),
+		swap:   b.swap,
+		extend: b.extend,
+		length: length,
+	}
+	// words are built in host order and converted at the end
+	head := copy(nb.vec, b.vec[:(b.length+wordBits-1)/wordBits])
+	for i := range nb.vec[:head] {
+		nb.vec[i] = b.conv(nb.vec[i])
+	}
+	if offset := b.length & (wordBits - 1); offset != 0 {
+		nb.vec[head-1] &^= allBits << offset
+	}
+	words, offset := int(b.length>>log2WordSize), b.length&(wordBits-1)
+	for j, v := range other.vec[:(other.length+wordBits-1)/wordBits] {
+		v = other.conv(v)
+		if rest := other.length - uint(j)*wordBits; rest < wordBits {
+			v &^= allBits << rest
+		}
+		nb.vec[words+j] |= v << offset
+		if offset != 0 && words+j+1 < len(nb.vec) {
+			nb.vec[words+j+1] |= v >> (wordBits - offset)
+		}
+	}
+	if nb.swap {
+		for i, v := range nb.vec {
+			nb.vec[i] = swapUint64(v)
+		}
+	}
+	return nb
+}
+
+// OrGrow creates *BitSet holding union of b and other sized to the longer one.
+// created BitSet has byte order of b and does not share memory with b and other.
+func (b *BitSet) OrGrow(other *BitSet) *BitSet {
+	size, length := len(b.vec), b.length
+	if len(other.vec) > size {
+		size = len(other.vec)
+	}
+	if other.length > length {
+		length = other.length
+	}
+	nb := &BitSet{
+		vec:    make([]uint64, size),
+		swap:   b.swap,
+		extend: b.extend,
+		length: length,
+	}
+	copy(nb.vec, b.vec)
+	if b.swap == other.swap {
+		for i, v := range other.vec {
+			nb.vec[i] |= v
+		}
+	} else {
+		for i, v := range other.vec {
+			nb.vec[i] |= swapUint64(v)
+		}
+	}
+	return nb
+}
+
+// Overlaps checks b and other have any common set bit.
+// it returns at the first common word.
+// if the word counts of b and other differ then returns ErrLengthMismatch.
+func (b *BitSet) Overlaps(other *BitSet) (bool, error) {
+	if len(b.vec) != len(other.vec) {
+		return false, ErrLengthMismatch
+	}
+	if b.swap == other.swap {
+		for i, v := range other.vec {
+			if b.vec[i]&v != 0 {
+				return true, nil
+			}
+		}
+	} else {
+		for i, v := range other.vec {
+			if b.vec[i]&swapUint64(v) != 0 {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// IsSubset checks all set bits of b are set in other.
+// if b is longer than other then extra words of b must be all 0.
+// vectors of different lengths are compared, so the error is always nil.
+func (b *BitSet) IsSubset(other *BitSet) (bool, error) {
+	n := len(b.vec)
+	if len(other.vec) < n {
+		n = len(other.vec)
+	}
+	for _, v := range b.vec[n:] {
+		if v != 0 {
+			return false, nil
+		}
+	}
+	if b.swap == other.swap {
+		for i, v := range b.vec[:n] {
+			if v&^other.vec[i] != 0 {
+				return false, nil
+			}
+		}
+	} else {
+		for i, v := range b.vec[:n] {
+			if v&^swapUint64(other.vec[i]) != 0 {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+// IsSuperset checks all set bits of other are set in b.
+// the error is always nil as IsSubset.
+func (b *BitSet) IsSuperset(other *BitSet) (bool, error) {
+	return other.IsSubset(b)
+}
+
+// Union writes union of x and y to dst in byte order of dst.
+// bits after BitLen() of dst are dropped.
+// x and y are not modified.
+func Union(dst, x, y *BitSet) error {
+	if len(x.vec) != len(dst.vec) || len(y.vec) != len(dst.vec) {
+		return ErrLengthMismatch
+	}
+	if aliasing(dst.vec, x.vec) || aliasing(dst.vec, y.vec) {
+		return ErrAliasingBuffers
+	}
+	for i := range dst.vec {
+		dst.vec[i] = dst.conv(x.conv(x.vec[i]) | y.conv(y.vec[i]))
+	}
+	dst.clearPadding()
+	dst.recount()
+	return nil
+}
+
+// Intersection writes intersection of x and y to dst in byte order of dst.
+// bits after BitLen() of dst are dropped.
+// x and y are not modified.
+func Intersection(dst, x, y *BitSet) error {
+	if len(x.vec) != len(dst.vec) || len(y.vec) != len(dst.vec) {
+		return ErrLengthMismatch
+	}
+	if aliasing(dst.vec, x.vec) || aliasing(dst.vec, y.vec) {
+		return ErrAliasingBuffers
+	}
+	for i := range dst.vec {
+		dst.vec[i] = dst.conv(x.conv(x.vec[i]) & y.conv(y.vec[i]))
+	}
+	dst.clearPadding()
+	dst.recount()
+	return nil
+}
+
+// AndCount returns the number of bits set in all of vecs.
+// vecs are not modified. if no vector is given then returns 0.
+func AndCount(vecs ...*BitSet) (uint, error) {
+	if len(vecs) == 0 {
+		return 0, nil
+	}
+	for _, v := range vecs[1:] {
+		if len(v.vec) != len(vecs[0].vec) {
+			return 0, ErrLengthMismatch
+		}
+	}
+	var cnt uint
+	for i := range vecs[0].vec {
+		w := vecs[0].conv(vecs[0].vec[i])
+		for _, v := range vecs[1:] {
+			if w == 0 {
+				break
+			}
+			w &= v.conv(v.vec[i])
+		}
+		cnt += uint(bits.OnesCount64(w))
+	}
+	return cnt, nil
+}
+
+// Jaccard returns Jaccard similarity |b & other| / |b | other|.
+// if both are empty then returns 0.
+func (b *BitSet) Jaccard(other *BitSet) (float64, error) {
+	inter, err := b.IntersectionCount(other)
+	if err != nil {
+		return 0, err
+	}
+	union := b.Count() + other.Count() - inter
+	if union == 0 {
+		return 0, nil
+	}
+	return float64(inter) / float64(union), nil
+}
@@ -0,0 +1,656 @@
+package bitset
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func newBitSetWith(t *testing.T, size int, order binary.ByteOrder, set []uint) *BitSet {
+	t.Helper()
+	b, err := New(make([]byte, size), order, false)
+	if err != nil {
+		t.Fatalf("failed to create bit vec %v", err)
+	}
+	for _, v := range set {
+		if !b.Set(v) {
+			t.Fatalf("failed to set %v", v)
+		}
+	}
+	return b
+}
+
+func assertBits(t *testing.T, b *BitSet, expected []uint) {
+	t.Helper()
+	set := make(map[uint]bool)
+	for _, v := range expected {
+		set[v] = true
+	}
+	for i := uint(0); i < uint(len(b.vec))*wordBits; i++ {
+		if b.Get(i) != set[i] {
+			t.Errorf("get(%v) == %v, expected %v", i, b.Get(i), set[i])
+		}
+	}
+}
+
+func TestBitSet_And(t *testing.T) {
+	for _, e1 := range endians {
+		for _, e2 := range endians {
+			t.Run(e1.String()+"_"+e2.String(), func(t *testing.T) {
+				b := newBitSetWith(t, 8*3, e1, []uint{0, 1, 3, 6, 10, 64, 127})
+				other := newBitSetWith(t, 8*3, e2, []uint{1, 2, 6, 11, 64, 128})
+				if err := b.And(other); err != nil {
+					t.Fatalf("failed to and %v", err)
+				}
+				assertBits(t, b, []uint{1, 6, 64})
+				assertBits(t, other, []uint{1, 2, 6, 11, 64, 128})
+
+				if err := b.And(newBitSetWith(t, 8*2, e2, nil)); err != ErrLengthMismatch {
+					t.Errorf("err == %v, expected %v", err, ErrLengthMismatch)
+				}
+			})
+		}
+	}
+}
+
+func TestBitSet_Or(t *testing.T) {
+	for _, e1 := range endians {
+		for _, e2 := range endians {
+			t.Run(e1.String()+"_"+e2.String(), func(t *testing.T) {
+				b := newBitSetWith(t, 8*3, e1, []uint{0, 1, 3, 64, 127})
+				other := newBitSetWith(t, 8*3, e2, []uint{1, 2, 11, 64, 128})
+				if err := b.Or(other); err != nil {
+					t.Fatalf("failed to or %v", err)
+				}
+				assertBits(t, b, []uint{0, 1, 2, 3, 11, 64, 127, 128})
+				assertBits(t, other, []uint{1, 2, 11, 64, 128})
+
+				if err := b.Or(newBitSetWith(t, 8*2, e2, nil)); err != ErrLengthMismatch {
+					t.Errorf("err == %v, expected %v", err, ErrLengthMismatch)
+				}
+			})
+		}
+	}
+}
+
+func TestBitSet_Xor(t *testing.T) {
+	for _, e1 := range endians {
+		for _, e2 := range endians {
+			t.Run(e1.String()+"_"+e2.String(), func(t *testing.T) {
+				b := newBitSetWith(t, 8*3, e1, []uint{0, 1, 3, 64, 127})
+				other := newBitSetWith(t, 8*3, e2, []uint{1, 2, 11, 64, 128})
+				if err := b.Xor(other); err != nil {
+					t.Fatalf("failed to xor %v", err)
+				}
+				assertBits(t, b, []uint{0, 2, 3, 11, 127, 128})
+				assertBits(t, other, []uint{1, 2, 11, 64, 128})
+
+				if err := b.Xor(newBitSetWith(t, 8*2, e2, nil)); err != ErrLengthMismatch {
+					t.Errorf("err == %v, expected %v", err, ErrLengthMismatch)
+				}
+			})
+		}
+	}
+}
+
+func TestBitSet_AndNot(t *testing.T) {
+	for _, e1 := range endians {
+		for _, e2 := range endians {
+			t.Run(e1.String()+"_"+e2.String(), func(t *testing.T) {
+				b := newBitSetWith(t, 8*3, e1, []uint{0, 1, 3, 64, 127})
+				other := newBitSetWith(t, 8*3, e2, []uint{1, 2, 11, 64, 128})
+				if err := b.AndNot(other); err != nil {
+					t.Fatalf("failed to and not %v", err)
+				}
+				assertBits(t, b, []uint{0, 3, 127})
+				assertBits(t, other, []uint{1, 2, 11, 64, 128})
+
+				if err := b.AndNot(newBitSetWith(t, 8*2, e2, nil)); err != ErrLengthMismatch {
+					t.Errorf("err == %v, expected %v", err, ErrLengthMismatch)
+				}
+			})
+		}
+	}
+}
+
+func TestBitSet_AndNotInto(t *testing.T) {
+	for _, e1 := range endians {
+		for _, e2 := range endians {
+			for _, e3 := range endians {
+				t.Run(e1.String()+"_"+e2.String()+"_"+e3.String(), func(t *testing.T) {
+					b := newBitSetWith(t, 8*3, e2, []uint{0, 1, 3, 64, 127})
+					mask := newBitSetWith(t, 8*3, e3, []uint{1, 2, 11, 64, 128})
+					dst := newBitSetWith(t, 8*3, e1, []uint{5, 100})
+					if err := b.AndNotInto(dst, mask); err != nil {
+						t.Fatalf("failed to and not %v", err)
+					}
+					assertBits(t, dst, []uint{0, 3, 127})
+					assertBits(t, b, []uint{0, 1, 3, 64, 127})
+					assertBits(t, mask, []uint{1, 2, 11, 64, 128})
+
+					short := newBitSetWith(t, 8*2, e1, nil)
+					if err := b.AndNotInto(short, mask); err != ErrLengthMismatch {
+						t.Errorf("err == %v, expected %v", err, ErrLengthMismatch)
+					}
+					if err := b.AndNotInto(dst, short); err != ErrLengthMismatch {
+						t.Errorf("err == %v, expected %v", err, ErrLengthMismatch)
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestBitSet_IntersectionCount(t *testing.T) {
+	for _, e1 := range endians {
+		for _, e2 := range endians {
+			t.Run(e1.String()+"_"+e2.String(), func(t *testing.T) {
+				b := newBitSetWith(t, 8*3, e1, []uint{0, 1, 3, 6, 10, 64, 127})
+				other := newBitSetWith(t, 8*3, e2, []uint{1, 2, 6, 11, 64, 128})
+				cnt, err := b.IntersectionCount(other)
+				if err != nil {
+					t.Fatalf("failed to count intersection %v", err)
+				}
+				if cnt != 3 {
+					t.Errorf("intersection count == %v, expected %v", cnt, 3)
+				}
+
+				if _, err := b.IntersectionCount(newBitSetWith(t, 8*2, e2, nil)); err != ErrLengthMismatch {
+					t.Errorf("err == %v, expected %v", err, ErrLengthMismatch)
+				}
+			})
+		}
+	}
+}
+
+func TestBitSet_Compare(t *testing.T) {
+	for _, e1 := range endians {
+		for _, e2 := range endians {
+			t.Run(e1.String()+"_"+e2.String(), func(t *testing.T) {
+				tests := []struct {
+					size1, size2 int
+					arr1, arr2   []uint
+					expected     int
+				}{
+					{size1: 8 * 2, size2: 8 * 2, arr1: nil, arr2: nil, expected: 0},
+					{size1: 8 * 2, size2: 8 * 2, arr1: []uint{0, 64}, arr2: []uint{0, 64}, expected: 0},
+					{size1: 8 * 2, size2: 8 * 2, arr1: []uint{1}, arr2: []uint{0}, expected: 1},
+					{size1: 8 * 2, size2: 8 * 2, arr1: []uint{8}, arr2: []uint{7}, expected: 1},
+					{size1: 8 * 2, size2: 8 * 2, arr1: []uint{64}, arr2: []uint{0, 1, 63}, expected: 1},
+					{size1: 8 * 2, size2: 8 * 2, arr1: []uint{3, 100}, arr2: []uint{100, 101}, expected: -1},
+					{size1: 8 * 2, size2: 8 * 3, arr1: []uint{0, 64}, arr2: []uint{0, 64}, expected: 0},
+					{size1: 8 * 2, size2: 8 * 3, arr1: []uint{127}, arr2: []uint{128}, expected: -1},
+					{size1: 8 * 3, size2: 8 * 2, arr1: []uint{128}, arr2: []uint{127}, expected: 1},
+				}
+				for _, test := range tests {
+					b := newBitSetWith(t, test.size1, e1, test.arr1)
+					other := newBitSetWith(t, test.size2, e2, test.arr2)
+					if result := b.Compare(other); result != test.expected {
+						t.Errorf("compare %v with %v == %v, expected %v", test.arr1, test.arr2, result, test.expected)
+					}
+					if result := other.Compare(b); result != -test.expected {
+						t.Errorf("compare %v with %v == %v, expected %v", test.arr2, test.arr1, result, -test.expected)
+					}
+					if (b.Compare(other) == 0) != b.Equal(other) {
+						t.Errorf("compare %v with %v does not match Equal", test.arr1, test.arr2)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestBitSet_EqualPrefix(t *testing.T) {
+	for _, e1 := range endians {
+		for _, e2 := range endians {
+			t.Run(e1.String()+"_"+e2.String(), func(t *testing.T) {
+				b := newBitSetWith(t, 8*2, e1, []uint{0, 1, 3, 64, 100, 127})
+				other := newBitSetWith(t, 8*3, e2, []uint{0, 1, 3, 64, 101, 150})
+				tests := []struct {
+					n        uint
+					expected bool
+				}{
+					{n: 0, expected: true},
+					{n: 64, expected: true},
+					{n: 100, expected: true},
+					{n: 101, expected: false},
+					{n: 128, expected: false},
+				}
+				for _, test := range tests {
+					result, err := b.EqualPrefix(other, test.n)
+					if err != nil {
+						t.Fatalf("failed to compare prefix %v", err)
+					}
+					if result != test.expected {
+						t.Errorf("equal prefix %v == %v, expected %v", test.n, result, test.expected)
+					}
+				}
+				if _, err := b.EqualPrefix(other, 129); err != ErrOutOfRange {
+					t.Errorf("err == %v, expected %v", err, ErrOutOfRange)
+				}
+				if !other.SetBitLen(90) {
+					t.Fatalf("failed to set bit len")
+				}
+				if _, err := b.EqualPrefix(other, 100); err != ErrOutOfRange {
+					t.Errorf("err == %v, expected %v", err, ErrOutOfRange)
+				}
+			})
+		}
+	}
+}
+
+func TestBitSet_Hash(t *testing.T) {
+	for _, e1 := range endians {
+		for _, e2 := range endians {
+			t.Run(e1.String()+"_"+e2.String(), func(t *testing.T) {
+				arr := []uint{0, 1, 3, 6, 10, 64, 127}
+				b := newBitSetWith(t, 8*2, e1, arr)
+				if b.Hash() != newBitSetWith(t, 8*2, e2, arr).Hash() {
+					t.Errorf("same bits have different hash")
+				}
+				if b.Hash() != newBitSetWith(t, 8*3, e2, arr).Hash() {
+					t.Errorf("same bits with zero tail have different hash")
+				}
+				if b.Hash() == newBitSetWith(t, 8*2, e2, arr[1:]).Hash() {
+					t.Errorf("different bits have the same hash")
+				}
+				if b.Hash() == newBitSetWith(t, 8*3, e2, append(arr, 130)).Hash() {
+					t.Errorf("different bits in tail have the same hash")
+				}
+			})
+		}
+	}
+	if h := newBitSetWith(t, 8*2, binary.BigEndian, nil).Hash(); h != fnvOffset64 {
+		t.Errorf("hash of empty vector == %v, expected %v", h, uint64(fnvOffset64))
+	}
+}
+
+func TestBitSet_DifferenceCount(t *testing.T) {
+	for _, e1 := range endians {
+		for _, e2 := range endians {
+			t.Run(e1.String()+"_"+e2.String(), func(t *testing.T) {
+				b := newBitSetWith(t, 8*3, e1, []uint{0, 1, 3, 6, 10, 64, 127})
+				other := newBitSetWith(t, 8*3, e2, []uint{1, 2, 6, 11, 64, 128})
+				cnt, err := b.DifferenceCount(other)
+				if err != nil {
+					t.Fatalf("failed to count difference %v", err)
+				}
+				if cnt != 4 {
+					t.Errorf("difference count == %v, expected %v", cnt, 4)
+				}
+				if cnt, _ := other.DifferenceCount(b); cnt != 3 {
+					t.Errorf("difference count == %v, expected %v", cnt, 3)
+				}
+				if allocs := testing.AllocsPerRun(10, func() { b.DifferenceCount(other) }); allocs != 0 {
+					t.Errorf("difference count allocates %v times", allocs)
+				}
+
+				if _, err := b.DifferenceCount(newBitSetWith(t, 8*2, e2, nil)); err != ErrLengthMismatch {
+					t.Errorf("err == %v, expected %v", err, ErrLengthMismatch)
+				}
+			})
+		}
+	}
+}
+
+func TestBitSet_Equal(t *testing.T) {
+	for _, e1 := range endians {
+		for _, e2 := range endians {
+			t.Run(e1.String()+"_"+e2.String(), func(t *testing.T) {
+				arr := []uint{0, 1, 3, 6, 10, 64, 127}
+				b := newBitSetWith(t, 8*2, e1, arr)
+				if !b.Equal(newBitSetWith(t, 8*2, e2, arr)) {
+					t.Errorf("same bits are not equal")
+				}
+				if b.Equal(newBitSetWith(t, 8*2, e2, arr[1:])) {
+					t.Errorf("different bits are equal")
+				}
+				if !b.Equal(newBitSetWith(t, 8*3, e2, arr)) {
+					t.Errorf("same bits with zero tail are not equal")
+				}
+				if b.Equal(newBitSetWith(t, 8*3, e2, append(arr, 130))) {
+					t.Errorf("different bits in tail are equal")
+				}
+				if !newBitSetWith(t, 8*3, e2, arr).Equal(b) {
+					t.Errorf("same bits with zero tail are not equal in reverse")
+				}
+			})
+		}
+	}
+}
+
+func TestBitSet_HammingDistance(t *testing.T) {
+	for _, e1 := range endians {
+		for _, e2 := range endians {
+			t.Run(e1.String()+"_"+e2.String(), func(t *testing.T) {
+				b := newBitSetWith(t, 8*3, e1, []uint{0, 1, 3, 6, 10, 64, 127})
+				other := newBitSetWith(t, 8*3, e2, []uint{1, 2, 6, 11, 64, 128})
+				cnt, err := b.HammingDistance(other)
+				if err != nil {
+					t.Fatalf("failed to calculate hamming distance %v", err)
+				}
+				if cnt != 7 {
+					t.Errorf("hamming distance == %v, expected %v", cnt, 7)
+				}
+				if cnt, _ := b.HammingDistance(b); cnt != 0 {
+					t.Errorf("hamming distance to itself == %v, expected 0", cnt)
+				}
+
+				if _, err := b.HammingDistance(newBitSetWith(t, 8*2, e2, nil)); err != ErrLengthMismatch {
+					t.Errorf("err == %v, expected %v", err, ErrLengthMismatch)
+				}
+			})
+		}
+	}
+}
+
+func TestBitSet_CopyFrom(t *testing.T) {
+	for _, e1 := range endians {
+		for _, e2 := range endians {
+			t.Run(e1.String()+"_"+e2.String(), func(t *testing.T) {
+				arr := []uint{0, 1, 3, 6, 10, 64, 127, 128}
+				b := newBitSetWith(t, 8*3, e1, []uint{2, 191})
+				n, err := b.CopyFrom(newBitSetWith(t, 8*3, e2, arr))
+				if err != nil || n != 3 {
+					t.Fatalf("copied %v words, err : %v, expected 3", n, err)
+				}
+				assertBits(t, b, arr)
+
+				n, err = b.CopyFrom(newBitSetWith(t, 8, e2, []uint{5}))
+				if err != nil || n != 1 {
+					t.Fatalf("copied %v words, err : %v, expected 1", n, err)
+				}
+				assertBits(t, b, []uint{5, 64, 127, 128})
+
+				short := newBitSetWith(t, 8, e1, nil)
+				n, err = short.CopyFrom(b)
+				if err != nil || n != 1 {
+					t.Fatalf("copied %v words, err : %v, expected 1", n, err)
+				}
+				assertBits(t, short, []uint{5})
+			})
+		}
+	}
+}
+
+func TestBitSet_Append(t *testing.T) {
+	for _, e1 := range endians {
+		for _, e2 := range endians {
+			t.Run(e1.String()+"_"+e2.String(), func(t *testing.T) {
+				arr1 := []uint{0, 1, 3, 63, 64, 99, 120}
+				arr2 := []uint{0, 2, 63, 64, 100, 149, 160}
+				for _, l1 := range []uint{0, 64, 100, 128} {
+					for _, l2 := range []uint{0, 64, 150, 192} {
+						b := newBitSetWith(t, 8*2, e1, arr1)
+						other := newBitSetWith(t, 8*3, e2, arr2)
+						if !b.SetBitLen(l1) || !other.SetBitLen(l2) {
+							t.Fatalf("failed to set bit len")
+						}
+						result := b.Append(other)
+						if result.BitLen() != l1+l2 || result.swap != b.swap {
+							t.Errorf("%v+%v : bit len == %v, swap == %v", l1, l2, result.BitLen(), result.swap)
+						}
+						var expected []uint
+						for _, v := range arr1 {
+							if v < l1 {
+								expected = append(expected, v)
+							}
+						}
+						for _, v := range arr2 {
+							if v < l2 {
+								expected = append(expected, l1+v)
+							}
+						}
+						assertBits(t, result, expected)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestBitSet_OrGrow(t *testing.T) {
+	for _, e1 := range endians {
+		for _, e2 := range endians {
+			t.Run(e1.String()+"_"+e2.String(), func(t *testing.T) {
+				short := newBitSetWith(t, 8*2, e1, []uint{0, 1, 3, 64, 127})
+				long := newBitSetWith(t, 8*3, e2, []uint{1, 2, 11, 64, 128, 191})
+				expected := []uint{0, 1, 2, 3, 11, 64, 127, 128, 191}
+				for _, pair := range [][2]*BitSet{{short, long}, {long, short}} {
+					result := pair[0].OrGrow(pair[1])
+					if result.WordCount() != 3 || result.BitLen() != 8*3*8 || result.swap != pair[0].swap {
+						t.Errorf("word count == %v, bit len == %v, swap == %v", result.WordCount(), result.BitLen(), result.swap)
+					}
+					assertBits(t, result, expected)
+					if !result.Set(5) || pair[0].Get(5) || pair[1].Get(5) {
+						t.Errorf("result shares memory with inputs")
+					}
+				}
+				assertBits(t, short, []uint{0, 1, 3, 64, 127})
+				assertBits(t, long, []uint{1, 2, 11, 64, 128, 191})
+			})
+		}
+	}
+}
+
+func TestBitSet_Overlaps(t *testing.T) {
+	for _, e1 := range endians {
+		for _, e2 := range endians {
+			t.Run(e1.String()+"_"+e2.String(), func(t *testing.T) {
+				b := newBitSetWith(t, 8*3, e1, []uint{0, 3, 64, 130})
+				tests := []struct {
+					set      []uint
+					expected bool
+				}{
+					{set: nil, expected: false},
+					{set: []uint{1, 2, 65, 129, 131}, expected: false},
+					{set: []uint{1, 130}, expected: true},
+					{set: []uint{0}, expected: true},
+				}
+				for _, test := range tests {
+					result, err := b.Overlaps(newBitSetWith(t, 8*3, e2, test.set))
+					if err != nil {
+						t.Fatalf("failed to check overlap %v", err)
+					}
+					if result != test.expected {
+						t.Errorf("overlaps with %v == %v, expected %v", test.set, result, test.expected)
+					}
+				}
+
+				if _, err := b.Overlaps(newBitSetWith(t, 8*2, e2, nil)); err != ErrLengthMismatch {
+					t.Errorf("err == %v, expected %v", err, ErrLengthMismatch)
+				}
+			})
+		}
+	}
+}
+
+func TestBitSet_IsSubset(t *testing.T) {
+	for _, e1 := range endians {
+		for _, e2 := range endians {
+			t.Run(e1.String()+"_"+e2.String(), func(t *testing.T) {
+				other := newBitSetWith(t, 8*2, e2, []uint{0, 3, 64, 100})
+				tests := []struct {
+					size     int
+					set      []uint
+					expected bool
+				}{
+					{size: 8 * 2, set: nil, expected: true},
+					{size: 8 * 2, set: []uint{0, 100}, expected: true},
+					{size: 8 * 2, set: []uint{0, 3, 64, 100}, expected: true},
+					{size: 8 * 2, set: []uint{0, 1}, expected: false},
+					{size: 8, set: []uint{3}, expected: true},
+					{size: 8 * 3, set: []uint{3, 64}, expected: true},
+					{size: 8 * 3, set: []uint{3, 130}, expected: false},
+				}
+				for _, test := range tests {
+					b := newBitSetWith(t, test.size, e1, test.set)
+					result, err := b.IsSubset(other)
+					if err != nil {
+						t.Fatalf("failed to check subset %v", err)
+					}
+					if result != test.expected {
+						t.Errorf("%v is subset == %v, expected %v", test.set, result, test.expected)
+					}
+					if result, _ := other.IsSuperset(b); result != test.expected {
+						t.Errorf("is superset of %v == %v, expected %v", test.set, result, test.expected)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestAndCount(t *testing.T) {
+	for _, e1 := range endians {
+		for _, e2 := range endians {
+			t.Run(e1.String()+"_"+e2.String(), func(t *testing.T) {
+				x := newBitSetWith(t, 8*3, e1, []uint{0, 1, 3, 64, 127, 128})
+				y := newBitSetWith(t, 8*3, e2, []uint{1, 2, 11, 64, 128})
+				z := newBitSetWith(t, 8*3, e1, []uint{1, 64, 100, 191})
+				tests := []struct {
+					vecs     []*BitSet
+					expected uint
+				}{
+					{vecs: nil, expected: 0},
+					{vecs: []*BitSet{x}, expected: 6},
+					{vecs: []*BitSet{x, y}, expected: 3},
+					{vecs: []*BitSet{x, y, z}, expected: 2},
+				}
+				for _, test := range tests {
+					cnt, err := AndCount(test.vecs...)
+					if err != nil {
+						t.Fatalf("failed to count %v", err)
+					}
+					if cnt != test.expected {
+						t.Errorf("and count of %v vectors == %v, expected %v", len(test.vecs), cnt, test.expected)
+					}
+				}
+				assertBits(t, x, []uint{0, 1, 3, 64, 127, 128})
+				if _, err := AndCount(x, y, newBitSetWith(t, 8*2, e2, nil)); err != ErrLengthMismatch {
+					t.Errorf("err == %v, expected %v", err, ErrLengthMismatch)
+				}
+			})
+		}
+	}
+}
+
+func TestUnion_Intersection(t *testing.T) {
+	for _, e1 := range endians {
+		for _, e2 := range endians {
+			for _, e3 := range endians {
+				t.Run(e1.String()+"_"+e2.String()+"_"+e3.String(), func(t *testing.T) {
+					x := newBitSetWith(t, 8*3, e2, []uint{0, 1, 3, 64, 127})
+					y := newBitSetWith(t, 8*3, e3, []uint{1, 2, 11, 64, 128})
+					dst := newBitSetWith(t, 8*3, e1, []uint{5, 100})
+					if err := Union(dst, x, y); err != nil {
+						t.Fatalf("failed to union %v", err)
+					}
+					assertBits(t, dst, []uint{0, 1, 2, 3, 11, 64, 127, 128})
+					if err := Intersection(dst, x, y); err != nil {
+						t.Fatalf("failed to intersect %v", err)
+					}
+					assertBits(t, dst, []uint{1, 64})
+					assertBits(t, x, []uint{0, 1, 3, 64, 127})
+					assertBits(t, y, []uint{1, 2, 11, 64, 128})
+
+					short := newBitSetWith(t, 8*2, e1, nil)
+					if err := Union(short, x, y); err != ErrLengthMismatch {
+						t.Errorf("err == %v, expected %v", err, ErrLengthMismatch)
+					}
+					if err := Intersection(dst, x, short); err != ErrLengthMismatch {
+						t.Errorf("err == %v, expected %v", err, ErrLengthMismatch)
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestBitSet_Jaccard(t *testing.T) {
+	for _, e1 := range endians {
+		for _, e2 := range endians {
+			t.Run(e1.String()+"_"+e2.String(), func(t *testing.T) {
+				tests := []struct {
+					x, y     []uint
+					expected float64
+				}{
+					{x: nil, y: nil, expected: 0},
+					{x: []uint{0, 1, 64}, y: nil, expected: 0},
+					{x: []uint{0, 1, 64}, y: []uint{0, 1, 64}, expected: 1},
+					{x: []uint{0, 1, 3, 64}, y: []uint{1, 64, 128, 130}, expected: 2.0 / 6.0},
+				}
+				for _, test := range tests {
+					result, err := newBitSetWith(t, 8*3, e1, test.x).Jaccard(newBitSetWith(t, 8*3, e2, test.y))
+					if err != nil {
+						t.Fatalf("failed to calculate jaccard %v", err)
+					}
+					if result != test.expected {
+						t.Errorf("jaccard of %v and %v == %v, expected %v", test.x, test.y, result, test.expected)
+					}
+				}
+				if _, err := newBitSetWith(t, 8*3, e1, nil).Jaccard(newBitSetWith(t, 8*2, e2, nil)); err != ErrLengthMismatch {
+					t.Errorf("err == %v, expected %v", err, ErrLengthMismatch)
+				}
+			})
+		}
+	}
+}
+
+func TestBitSet_Aliasing(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			buf := make([]byte, 8*4)
+			b, err := New(buf[:8*3], endian, false)
+			if err != nil {
+				t.Fatalf("failed to create bit vec %v", err)
+			}
+			shifted, err := New(buf[8:], endian, false)
+			if err != nil {
+				t.Fatalf("failed to create bit vec %v", err)
+			}
+			ops := map[string]func() error{
+				"And":          func() error { return b.And(shifted) },
+				"Or":           func() error { return shifted.Or(b) },
+				"Xor":          func() error { return b.Xor(shifted) },
+				"AndNot":       func() error { return b.AndNot(shifted) },
+				"AndNotInto":   func() error { return b.AndNotInto(shifted, b) },
+				"Union":        func() error { return Union(b, shifted, b) },
+				"Intersection": func() error { return Intersection(shifted, b, b) },
+			}
+			for name, op := range ops {
+				if err := op(); err != ErrAliasingBuffers {
+					t.Errorf("%v : err == %v, expected %v", name, err, ErrAliasingBuffers)
+				}
+			}
+
+			for _, v := range []uint{0, 1, 64, 127} {
+				b.Set(v)
+			}
+			if err := b.And(b); err != nil {
+				t.Errorf("failed to and itself %v", err)
+			}
+			if err := b.Or(b.Clone()); err != nil {
+				t.Errorf("failed to or clone %v", err)
+			}
+			assertBits(t, b, []uint{0, 1, 64, 127})
+
+			// same byte order is copied as memmove
+			if n, err := shifted.CopyFrom(b); err != nil || n != 3 {
+				t.Errorf("failed to copy overlapping words n : %v, err : %v", n, err)
+			}
+			assertBits(t, shifted, []uint{0, 1, 64, 127})
+
+			opposite := endians[0]
+			if opposite == endian {
+				opposite = endians[1]
+			}
+			other, err := New(buf[8:], opposite, false)
+			if err != nil {
+				t.Fatalf("failed to create bit vec %v", err)
+			}
+			if _, err := other.CopyFrom(b); err != ErrAliasingBuffers {
+				t.Errorf("err == %v, expected %v", err, ErrAliasingBuffers)
+			}
+		})
+	}
+}
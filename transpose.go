@@ -0,0 +1,58 @@
+package bitset
+
+import (
+	"math/bits"
+)
+
+// TransposeSquare transposes n x n bit matrix stored in row major order,
+// bit (r, c) at r * n + c moves to c * n + r.
+// if n is not multiple of 8 or n * n is not BitLen() then returns ErrInvalidLength.
+func (b *BitSet) TransposeSquare(n uint) error {
+	// n * n must not overflow
+	if n%8 != 0 || n >= 1<<(bits.UintSize/2) || n*n != b.length {
+		return ErrInvalidLength
+	}
+	blocks := n / 8
+	// matrix is transposed by 8 x 8 blocks, blocks of (br, bc) and (bc, br) are swapped
+	for br := uint(0); br < blocks; br++ {
+		for bc := br; bc < blocks; bc++ {
+			x, y := b.block8(n, br, bc), b.block8(n, bc, br)
+			b.setBlock8(n, br, bc, transpose8(y))
+			if br != bc {
+				b.setBlock8(n, bc, br, transpose8(x))
+			}
+		}
+	}
+	return nil
+}
+
+// block8 returns 8 x 8 block at (br, bc) with row i in byte i.
+func (b *BitSet) block8(n, br, bc uint) uint64 {
+	var x uint64
+	for i := uint(0); i < 8; i++ {
+		k := (br*8+i)*n/8 + bc
+		x |= (b.conv(b.vec[k>>3]) >> ((k & 7) * 8) & 0xff) << (i * 8)
+	}
+	return x
+}
+
+// setBlock8 writes 8 x 8 block x at (br, bc).
+func (b *BitSet) setBlock8(n, br, bc uint, x uint64) {
+	for i := uint(0); i < 8; i++ {
+		k := (br*8+i)*n/8 + bc
+		shift := (k & 7) * 8
+		v := b.conv(b.vec[k>>3])
+		v = v&^(0xff<<shift) | (x>>(i*8)&0xff)<<shift
+		b.vec[k>>3] = b.conv(v)
+	}
+}
+
+// transpose8 transposes 8 x 8 bit matrix, bit 8 * i + j moves to 8 * j + i.
+func transpose8(x uint64) uint64 {
+	t := (x ^ (x >> 7)) & 0x00aa00aa00aa00aa
+	x = x ^ t ^ (t << 7)
+	t = (x ^ (x >> 14)) & 0x0000cccc0000cccc
+	x = x ^ t ^ (t << 14)
+	t = (x ^ (x >> 28)) & 0x00000000f0f0f0f0
+	return x ^ t ^ (t << 28)
+}
@@ -0,0 +1,61 @@
+package bitset
+
+import (
+	"math/bits"
+	"math/rand"
+	"testing"
+)
+
+func TestBitSet_TransposeSquare(t *testing.T) {
+	for _, endian := range endians {
+		t.Run(endian.String(), func(t *testing.T) {
+			for _, n := range []uint{8, 16, 24, 64, 72} {
+				b := newBitSetWith(t, int((n*n+63)/64*8), endian, nil)
+				if !b.SetBitLen(n * n) {
+					t.Fatalf("failed to set bit len %v", n*n)
+				}
+				rnd := rand.New(rand.NewSource(int64(n)))
+				for i := uint(0); i < n*n; i++ {
+					if rnd.Intn(3) == 0 {
+						b.Set(i)
+					}
+				}
+				orig := b.Clone()
+				if err := b.TransposeSquare(n); err != nil {
+					t.Fatalf("failed to transpose %v", err)
+				}
+				for r := uint(0); r < n; r++ {
+					for c := uint(0); c < n; c++ {
+						if b.Get(c*n+r) != orig.Get(r*n+c) {
+							t.Errorf("n %v : get(%v, %v) == %v, expected %v", n, c, r, b.Get(c*n+r), orig.Get(r*n+c))
+						}
+					}
+				}
+				if err := b.TransposeSquare(n); err != nil || !b.Equal(orig) {
+					t.Errorf("n %v : transposed twice does not match err : %v", n, err)
+				}
+			}
+
+			b := newBitSetWith(t, 8*2, endian, nil)
+			if err := b.TransposeSquare(8); err != ErrInvalidLength {
+				t.Errorf("err : %v, expected err : %v", err, ErrInvalidLength)
+			}
+			if !b.SetBitLen(100) {
+				t.Fatalf("failed to set bit len")
+			}
+			if err := b.TransposeSquare(10); err != ErrInvalidLength {
+				t.Errorf("err : %v, expected err : %v", err, ErrInvalidLength)
+			}
+
+			empty := newBitSetWith(t, 0, endian, nil)
+			for _, n := range []uint{1 << (bits.UintSize / 2), 3 << (bits.UintSize / 2)} {
+				if err := empty.TransposeSquare(n); err != ErrInvalidLength {
+					t.Errorf("n %v : err : %v, expected err : %v", n, err, ErrInvalidLength)
+				}
+			}
+			if err := empty.TransposeSquare(0); err != nil {
+				t.Errorf("failed to transpose empty matrix %v", err)
+			}
+		})
+	}
+}